@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// archiveResult is the output record of a session archived into Video Cloud.
+type archiveResult struct {
+	SessionID   string `json:"session_id"`
+	ResourceID  string `json:"resource_id"`
+	StartTime   int    `json:"start_time"`
+	EndTime     int    `json:"end_time"`
+	VODURL      string `json:"vod_url"`
+	AccountID   string `json:"target_account_id"`
+	VideoID     string `json:"video_id"`
+	IngestJobID string `json:"ingest_job_id"`
+	State       string `json:"state"`
+	Error       string `json:"error,omitempty"`
+}
+
+// archiveOptions control where and how archived sessions land in Video Cloud.
+type archiveOptions struct {
+	accountID    string
+	folderID     string
+	profile      string
+	video        videoFlags
+	wait         bool
+	pollInterval time.Duration
+}
+
+func runArchive(args []string) {
+	fs := flag.NewFlagSet("vodurls archive", flag.ExitOnError)
+	setUsage(fs, "Archives the sessions of a live resource into Video Cloud with Dynamic Ingest.",
+		"./vodurls archive [--target-account ID] [--folder ID] [--ingest-profile NAME] <PLAYBACK_URL>",
+		"./vodurls archive [flags] --account ID --resource ID",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	var rf resourceFlags
+	rf.register(fs)
+	var af auditFlags
+	af.register(fs)
+	output := fs.String("output", outputText, "output format (text or json)")
+	targetAccount := fs.String("target-account", "", "Video Cloud account to ingest into, defaults to the live resource's account")
+	folder := fs.String("folder", "", "Video Cloud folder ID to put the archived videos in")
+	profile := fs.String("ingest-profile", "", "ingest profile, defaults to the account's default profile")
+	var vf videoFlags
+	vf.register(fs)
+	noWait := fs.Bool("no-wait", false, "submit the ingest jobs without waiting for them to finish")
+	pollInterval := fs.Duration("poll-interval", 30*time.Second, "how often to check the status of the ingest jobs")
+	var sessionIDs stringList
+	fs.Var(&sessionIDs, "session", "only archive the session with this ID (repeatable)")
+	sessionIndex := -1
+	fs.Var((*indexFlag)(&sessionIndex), "session-index", "only archive the session at this 0-based position in the resource's session list")
+	latest := fs.Int("latest", 0, "only archive the N most recent ended sessions")
+	oldest := fs.Int("oldest", 0, "only archive the N earliest ended sessions")
+	trimStart := fs.String("trim-start", "", "cut this much from the start of each session (e.g. 90s or 2m), or start at this absolute epoch time")
+	trimEnd := fs.String("trim-end", "", "cut this much from the end of each session (e.g. 5m), or end at this absolute epoch time")
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() == 0 && !rf.set() {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if *output != outputText && *output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", *output)
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if *pollInterval <= 0 {
+		slog.Error("poll interval must be positive", "poll_interval", *pollInterval)
+		exit(1)
+	}
+
+	selection := sessionSelection{ids: sessionIDs, index: sessionIndex, latest: *latest, oldest: *oldest}
+	if err := selection.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	trim, err := parseTrimRange(*trimStart, *trimEnd)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	auditLog, err := af.open()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	if auditLog != nil {
+		defer auditLog.Close()
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	accountID, resourceID, err := rf.resolve(ctx, client, cf.profile.AccountID, fs.Arg(0))
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	// Dynamic Ingest pulls a single rendition set, HLS is what it ingests best
+	results, err := generate(ctx, client, accountID, resourceID, generateOptions{
+		formats:   []string{brightcove.ManifestFormatHLS},
+		selection: selection,
+		trim:      trim,
+		audit:     auditLog,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	opts := archiveOptions{
+		accountID:    *targetAccount,
+		folderID:     *folder,
+		profile:      *profile,
+		video:        vf,
+		wait:         !*noWait,
+		pollInterval: *pollInterval,
+	}
+
+	var archived []archiveResult
+	var failed int
+	for _, result := range results {
+		a := archiveSession(ctx, client, result, opts)
+		if a.Error != "" {
+			failed++
+		}
+		archived = append(archived, a)
+	}
+
+	if *output == outputJSON {
+		err = writeJSON(os.Stdout, archived)
+	} else {
+		err = writeArchiveText(os.Stdout, archived)
+	}
+	if err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+
+	if failed > 0 {
+		slog.Error("some sessions failed to archive", "failed", failed, "total", len(archived))
+		exit(1)
+	}
+}
+
+// archiveSession creates a Video Cloud video for a session and ingests its
+// VOD URL into it.
+func archiveSession(ctx context.Context, client *brightcove.Client, result sessionResult, opts archiveOptions) archiveResult {
+	a := archiveResult{
+		SessionID:  result.SessionID,
+		ResourceID: result.ResourceID,
+		StartTime:  result.StartTime,
+		EndTime:    result.EndTime,
+		VODURL:     result.URLs[0].URL,
+		AccountID:  opts.accountID,
+	}
+	if a.AccountID == "" {
+		a.AccountID = result.AccountID
+	}
+
+	fail := func(msg string, err error) archiveResult {
+		slog.ErrorContext(ctx, msg, "session_id", a.SessionID, "err", err)
+		a.Error = fmt.Sprintf("%s: %v", msg, err)
+		return a
+	}
+
+	name := fmt.Sprintf("%s %s", result.ResourceID, time.Unix(int64(result.StartTime), 0).UTC().Format(time.RFC3339))
+	video, err := client.CreateVideo(ctx, a.AccountID, opts.video.video(name))
+	if err != nil {
+		return fail("error creating video", err)
+	}
+	a.VideoID = video.ID
+
+	if opts.folderID != "" {
+		if err := client.AddVideoToFolder(ctx, a.AccountID, opts.folderID, video.ID); err != nil {
+			return fail("error adding video to folder", err)
+		}
+	}
+
+	job, err := client.Ingest(ctx, a.AccountID, video.ID, brightcove.IngestRequest{
+		URL:     a.VODURL,
+		Profile: opts.profile,
+	})
+	if err != nil {
+		return fail("error submitting ingest request", err)
+	}
+	a.IngestJobID = job.ID
+	a.State = job.State
+
+	if !opts.wait {
+		return a
+	}
+
+	slog.InfoContext(ctx, "waiting for ingest", "session_id", a.SessionID, "video_id", a.VideoID, "job_id", job.ID)
+	job, err = client.WaitForIngest(ctx, a.AccountID, video.ID, job.ID, opts.pollInterval)
+	if job != nil {
+		a.State = job.State
+	}
+	if err != nil {
+		return fail("error ingesting video", err)
+	}
+
+	return a
+}
+
+func writeArchiveText(w io.Writer, archived []archiveResult) error {
+	for i, a := range archived {
+		fmt.Fprintln(w)
+		if a.Error != "" {
+			fmt.Fprintf(w, "ARCHIVE[%d]: session %s FAILED: %s\n", i, a.SessionID, a.Error)
+			continue
+		}
+		fmt.Fprintf(w, "ARCHIVE[%d]: session %s, video ID %s, ingest job %s, %s\n", i, a.SessionID, a.VideoID, a.IngestJobID, a.State)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os/user"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/audit"
+)
+
+const defaultAuditLog = "~/.bc-vod-urls-audit.jsonl"
+
+// auditFlags configure the audit log of the commands generating VOD URLs.
+type auditFlags struct {
+	path       string
+	disabled   bool
+	maxSizeMB  int
+	maxBackups int
+	operator   string
+}
+
+func (f *auditFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.path, "audit-log", envString("AUDIT_LOG", defaultAuditLog), "append every generated playback token and VOD URL to this JSON lines file (env AUDIT_LOG)")
+	fs.BoolVar(&f.disabled, "no-audit-log", false, "do not write the audit log")
+	fs.IntVar(&f.maxSizeMB, "audit-max-size", envInt("AUDIT_MAX_SIZE", 100), "rotate the audit log once it reaches this many megabytes, 0 never rotates (env AUDIT_MAX_SIZE)")
+	fs.IntVar(&f.maxBackups, "audit-max-backups", envInt("AUDIT_MAX_BACKUPS", 10), "number of rotated audit logs to keep, 0 keeps all of them (env AUDIT_MAX_BACKUPS)")
+	fs.StringVar(&f.operator, "operator", envString("AUDIT_OPERATOR", ""), "operator recorded in the audit log, defaults to the OS user (env AUDIT_OPERATOR)")
+}
+
+// open opens the audit log, or returns nil if it is disabled.
+func (f *auditFlags) open() (*auditLog, error) {
+	if f.disabled || f.path == "" {
+		return nil, nil
+	}
+	if f.maxSizeMB < 0 || f.maxBackups < 0 {
+		return nil, fmt.Errorf("invalid audit log rotation, --audit-max-size and --audit-max-backups must not be negative")
+	}
+
+	operator := f.operator
+	if operator == "" {
+		if u, err := user.Current(); err == nil {
+			operator = u.Username
+		}
+	}
+
+	log, err := audit.Open(expandHome(f.path), int64(f.maxSizeMB)<<20, f.maxBackups)
+	if err != nil {
+		return nil, err
+	}
+	return &auditLog{Log: log, operator: operator}, nil
+}
+
+// auditLog records generated URLs on behalf of an operator.
+type auditLog struct {
+	*audit.Log
+	operator string
+}
+
+// record appends the generated URLs to the audit log. Unlike the history,
+// URLs that can't be audited are not handed out, so failing to record them
+// fails the generation.
+func (l *auditLog) record(results []sessionResult) error {
+	now := time.Now().UTC()
+	var entries []audit.Entry
+	for _, result := range results {
+		for _, url := range result.URLs {
+			digest := sha256.Sum256([]byte(url.Token))
+			entries = append(entries, audit.Entry{
+				Time:        now,
+				Operator:    l.operator,
+				AccountID:   result.AccountID,
+				ResourceID:  result.ResourceID,
+				SessionID:   result.SessionID,
+				Format:      url.Format,
+				TokenSHA256: hex.EncodeToString(digest[:]),
+				URL:         url.URL,
+			})
+		}
+	}
+
+	if err := l.Record(entries); err != nil {
+		return fmt.Errorf("error recording audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// inputResult is the outcome of processing one playback URL in batch mode.
+type inputResult struct {
+	PlaybackURL string `json:"playback_url"`
+	AccountID   string `json:"account_id,omitempty"`
+	ResourceID  string `json:"resource_id,omitempty"`
+	Error       string `json:"error,omitempty"`
+	// ErrorInfo is Error, classified.
+	ErrorInfo *errorInfo      `json:"error_info,omitempty"`
+	Sessions  []sessionResult `json:"sessions,omitempty"`
+
+	err error
+}
+
+func (r *inputResult) setError(err error) {
+	r.Error = err.Error()
+	r.ErrorInfo = newErrorInfo(err)
+	r.err = err
+}
+
+type batchResult []inputResult
+
+func (b batchResult) failed() int {
+	var n int
+	for _, r := range b {
+		if r.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// exitCode is the exit code of a batch in which every URL failed or was
+// skipped: the code the failures share, or exitFailure when they failed for
+// different reasons.
+func (b batchResult) exitCode() int {
+	code := -1
+	for _, r := range b {
+		if errors.Is(r.err, errSkipped) {
+			continue
+		}
+		c := exitCode(r.err)
+		if code != -1 && c != code {
+			return exitFailure
+		}
+		code = c
+	}
+	if code == -1 {
+		return exitFailure
+	}
+	return code
+}
+
+func (b batchResult) unverified() int {
+	var n int
+	for _, r := range b {
+		n += unverified(r.Sessions)
+	}
+	return n
+}
+
+// errSkipped marks playback URLs --fail-fast did not process.
+var errSkipped = errors.New("skipped after an earlier failure")
+
+// readInputs reads playback URLs, one per line, from path or stdin when path is
+// "-". Blank lines and lines starting with # are ignored.
+func readInputs(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no playback URLs found in %s", path)
+	}
+
+	return urls, nil
+}
+
+// dedupeResources drops the playback URLs that point at the same resource as
+// an earlier one, every session of a resource is generated once however many
+// of its playback URLs are given. URLs that can't be parsed are kept so they
+// are reported as failed.
+func dedupeResources(playbackURLs []string) []string {
+	seen := map[string]string{}
+	deduped := make([]string, 0, len(playbackURLs))
+	for _, playbackURL := range playbackURLs {
+		accountID, resourceID, err := brightcove.ParsePlaybackURL(playbackURL)
+		if err == nil {
+			key := accountID + "/" + resourceID
+			if first, ok := seen[key]; ok {
+				slog.Info("skipping playback URL of an already listed resource", "playback_url", playbackURL, "resource_id", resourceID, "first_playback_url", first)
+				continue
+			}
+			seen[key] = playbackURL
+		}
+		deduped = append(deduped, playbackURL)
+	}
+	return deduped
+}
+
+// runBatch generates VOD URLs for every playback URL, sharing the client's
+// access token. Up to opts.batchConcurrency URLs are processed in parallel,
+// taking turns between accounts and with at most opts.accountConcurrency in
+// flight per account. A failing URL is recorded and does not stop the rest of
+// the batch, once ctx is done the remaining URLs are marked as failed without
+// being tried. URLs completed in opts.checkpoint reuse their recorded result,
+// the others are recorded as they complete. Results are returned in input
+// order, and written to opts.stream in order of completion.
+func runBatch(ctx context.Context, client *brightcove.Client, playbackURLs []string, opts generateOptions) batchResult {
+	batch := make(batchResult, len(playbackURLs))
+	for i, playbackURL := range playbackURLs {
+		batch[i].PlaybackURL = playbackURL
+		batch[i].AccountID, batch[i].ResourceID, _ = brightcove.ParsePlaybackURL(playbackURL)
+	}
+
+	queue := newAccountQueue(batch, opts.accountConcurrency)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for range max(opts.batchConcurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i, ok := queue.next()
+				if !ok {
+					return
+				}
+				result := &batch[i]
+
+				switch {
+				case ctx.Err() != nil:
+					result.setError(ctx.Err())
+				case opts.failFast && failed.Load():
+					result.setError(errSkipped)
+					opts.summary.addSkipped(1)
+				default:
+					if completed, ok := opts.checkpoint.completed(result.PlaybackURL); ok {
+						result.Sessions = completed.Sessions
+						opts.progress.add(ctx, false)
+						break
+					}
+
+					sessions, err := generateURL(ctx, client, result.PlaybackURL, opts)
+					if err != nil {
+						slog.Error("error processing playback URL", "playback_url", result.PlaybackURL, "err", err)
+						result.setError(err)
+						// Only the expired sessions of table output
+						result.Sessions = sessions
+						opts.summary.addFailure(result.PlaybackURL, err)
+						failed.Store(true)
+					} else {
+						result.Sessions = sessions
+						if err := opts.checkpoint.record(*result); err != nil {
+							slog.Warn("error recording checkpoint", "playback_url", result.PlaybackURL, "err", err)
+						}
+					}
+					opts.progress.add(ctx, err != nil)
+				}
+				opts.stream.writeInput(*result)
+				queue.done(i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return batch
+}
+
+// accountQueue hands out the playback URLs of a batch to its workers, one
+// account after the other so a large account doesn't hold up the others,
+// and with at most limit URLs of an account in flight, any number when limit
+// is 0.
+// URLs that can't be parsed share the empty account ID.
+type accountQueue struct {
+	mu    sync.Mutex
+	ready *sync.Cond
+	limit int
+
+	accounts []string // in order of first appearance
+	turn     int      // index in accounts of the next account to serve
+	pending  map[string][]int
+	inFlight map[string]int
+	account  []string // account ID of every input
+	left     int
+}
+
+func newAccountQueue(batch batchResult, limit int) *accountQueue {
+	q := &accountQueue{
+		limit:    limit,
+		pending:  map[string][]int{},
+		inFlight: map[string]int{},
+		account:  make([]string, len(batch)),
+		left:     len(batch),
+	}
+	q.ready = sync.NewCond(&q.mu)
+	for i, result := range batch {
+		if _, ok := q.pending[result.AccountID]; !ok {
+			q.accounts = append(q.accounts, result.AccountID)
+		}
+		q.pending[result.AccountID] = append(q.pending[result.AccountID], i)
+		q.account[i] = result.AccountID
+	}
+	return q
+}
+
+// next returns the index of the next input to process, waiting while every
+// account with inputs left is at its limit. It returns false once every input
+// was handed out.
+func (q *accountQueue) next() (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.left > 0 {
+		for n := range len(q.accounts) {
+			k := (q.turn + n) % len(q.accounts)
+			account := q.accounts[k]
+			if len(q.pending[account]) == 0 || (q.limit > 0 && q.inFlight[account] >= q.limit) {
+				continue
+			}
+			i := q.pending[account][0]
+			q.pending[account] = q.pending[account][1:]
+			q.inFlight[account]++
+			q.turn = (k + 1) % len(q.accounts)
+			q.left--
+			return i, true
+		}
+		q.ready.Wait()
+	}
+	return 0, false
+}
+
+// done marks the input at index i as processed, freeing a slot of its
+// account.
+func (q *accountQueue) done(i int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.inFlight[q.account[i]]--
+	q.ready.Broadcast()
+}
+
+func writeBatchText(w io.Writer, batch batchResult) error {
+	for _, result := range batch {
+		switch {
+		case result.ResourceID != "" && result.PlaybackURL != "":
+			fmt.Fprintf(w, "\nResource %s: %s\n", result.ResourceID, result.PlaybackURL)
+		case result.ResourceID != "":
+			fmt.Fprintf(w, "\nResource %s\n", result.ResourceID)
+		default:
+			fmt.Fprintf(w, "\n%s\n", result.PlaybackURL)
+		}
+		if result.Error != "" {
+			fmt.Fprintf(w, "FAILED: %s\n", result.Error)
+			continue
+		}
+		if err := writeText(w, result.Sessions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
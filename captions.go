@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/hls"
+)
+
+// undeterminedLanguage stands for caption tracks that don't declare their
+// language.
+const undeterminedLanguage = "und"
+
+// cea608Scheme is the DASH accessibility scheme of CEA-608 captions carried in
+// the video, its value listing the channels as CC1=eng;CC3=spa.
+const cea608Scheme = "urn:scte:dash:cc:cea-608:2015"
+
+// hlsCaptions returns the languages of the subtitle and closed caption
+// renditions of an HLS master playlist, sorted and deduplicated. A media
+// playlist doesn't list the renditions of its VOD, so they are unknown: it
+// returns nil.
+func hlsCaptions(body []byte, url string) ([]string, error) {
+	if !hls.IsMaster(body) {
+		return nil, nil
+	}
+	master, err := hls.ParseMaster(body, url)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing master playlist: %w", err)
+	}
+
+	languages := []string{}
+	for _, m := range master.Media {
+		if m.Type != "SUBTITLES" && m.Type != "CLOSED-CAPTIONS" {
+			continue
+		}
+		languages = append(languages, captionLanguage(m.Language, m.Name))
+	}
+	slices.Sort(languages)
+	return slices.Compact(languages), nil
+}
+
+type mpdCaptions struct {
+	Periods []struct {
+		AdaptationSets []struct {
+			ContentType     string `xml:"contentType,attr"`
+			MimeType        string `xml:"mimeType,attr"`
+			Lang            string `xml:"lang,attr"`
+			Label           string `xml:"Label"`
+			Accessibilities []struct {
+				SchemeIDURI string `xml:"schemeIdUri,attr"`
+				Value       string `xml:"value,attr"`
+			} `xml:"Accessibility"`
+			Representations []struct {
+				MimeType string `xml:"mimeType,attr"`
+			} `xml:"Representation"`
+		} `xml:"AdaptationSet"`
+	} `xml:"Period"`
+}
+
+// dashCaptions returns the languages of the text adaptation sets of a DASH
+// manifest and of the CEA-608 captions its video declares, sorted and
+// deduplicated.
+func dashCaptions(body []byte) ([]string, error) {
+	var mpd mpdCaptions
+	if err := xml.NewDecoder(bytes.NewReader(body)).Decode(&mpd); err != nil {
+		return nil, fmt.Errorf("error parsing MPD manifest: %w", err)
+	}
+
+	languages := []string{}
+	for _, period := range mpd.Periods {
+		for _, set := range period.AdaptationSets {
+			mimeType := set.MimeType
+			if mimeType == "" && len(set.Representations) > 0 {
+				mimeType = set.Representations[0].MimeType
+			}
+			if set.ContentType == "text" || strings.HasPrefix(mimeType, "text/") || mimeType == "application/ttml+xml" {
+				languages = append(languages, captionLanguage(set.Lang, set.Label))
+			}
+
+			for _, a := range set.Accessibilities {
+				if a.SchemeIDURI != cea608Scheme {
+					continue
+				}
+				for _, channel := range strings.Split(a.Value, ";") {
+					_, lang, _ := strings.Cut(channel, "=")
+					languages = append(languages, captionLanguage(lang))
+				}
+			}
+		}
+	}
+	slices.Sort(languages)
+	return slices.Compact(languages), nil
+}
+
+// captionLanguage returns the first of names that is set, undeterminedLanguage
+// if none is.
+func captionLanguage(names ...string) string {
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+	return undeterminedLanguage
+}
+
+// captionsNote lists the caption languages --verify found in text output.
+func captionsNote(url resultURL) string {
+	if url.Verified == nil || !*url.Verified {
+		return ""
+	}
+	return "  captions: " + captionsList(url.Captions)
+}
+
+// captionsList joins the caption languages of a verified manifest, nil
+// meaning they are unknown.
+func captionsList(languages []string) string {
+	switch {
+	case languages == nil:
+		return "unknown"
+	case len(languages) == 0:
+		return "none"
+	}
+	return strings.Join(languages, ", ")
+}
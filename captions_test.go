@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+const (
+	testMasterPlaylist = `#EXTM3U
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",LANGUAGE="en",URI="subs-en.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="Español",LANGUAGE="es",URI="subs-es.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=2000000,RESOLUTION=1280x720,SUBTITLES="subs"
+media-720.m3u8
+`
+	testMediaPlaylist = `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXTINF:6.0,
+segment-1.ts
+#EXT-X-ENDLIST
+`
+)
+
+func TestHLSCaptions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "master", body: testMasterPlaylist, want: []string{"en", "es"}},
+		{name: "master without captions", body: "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=2000000\nmedia.m3u8\n", want: []string{}},
+		{name: "media playlist", body: testMediaPlaylist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hlsCaptions([]byte(tt.body), "https://example.com/playlist.m3u8")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRenditionCaptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/playlist.m3u8":
+			w.Write([]byte(testMasterPlaylist))
+		case "/media-720.m3u8":
+			w.Write([]byte(testMediaPlaylist))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		url  resultURL
+		note string
+	}{
+		{name: "master", url: resultURL{URL: srv.URL + "/playlist.m3u8"}, note: "  captions: en, es"},
+		{name: "rendition", url: resultURL{URL: srv.URL + "/media-720.m3u8", MasterURL: srv.URL + "/playlist.m3u8"}, note: "  captions: en, es"},
+		{name: "master unavailable", url: resultURL{URL: srv.URL + "/media-720.m3u8", MasterURL: srv.URL + "/gone.m3u8"}, note: "  captions: unknown"},
+		{name: "media playlist", url: resultURL{URL: srv.URL + "/media-720.m3u8"}, note: "  captions: unknown"},
+		{name: "unverified", url: resultURL{URL: srv.URL + "/gone.m3u8"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.url.Format = brightcove.ManifestFormatHLS
+			results := []sessionResult{{SessionID: "1", URLs: []resultURL{tt.url}}}
+			verifyResults(context.Background(), srv.Client(), results)
+			if got := captionsNote(results[0].URLs[0]); got != tt.note {
+				t.Errorf("got note %q, want %q", got, tt.note)
+			}
+		})
+	}
+}
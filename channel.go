@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// channelWindow is the time range --channel generates VOD URLs for, cut out of
+// the sessions of an always-on resource instead of following their
+// boundaries. Its epoch times are in seconds.
+type channelWindow struct {
+	start int
+	end   int
+}
+
+// parseChannelWindow parses --start and --end. The window must have ended by
+// now, an empty end is now.
+func parseChannelWindow(start, end string, now time.Time) (*channelWindow, error) {
+	if start == "" {
+		return nil, errors.New("--channel requires --start")
+	}
+	w := &channelWindow{end: int(now.Unix())}
+	var err error
+	if w.start, err = parseWindowTime(start, now); err != nil {
+		return nil, fmt.Errorf("invalid --start: %w", err)
+	}
+	if end != "" {
+		if w.end, err = parseWindowTime(end, now); err != nil {
+			return nil, fmt.Errorf("invalid --end: %w", err)
+		}
+	}
+
+	if w.start >= w.end {
+		return nil, errors.New("--start must be before --end")
+	}
+	if w.end > int(now.Unix()) {
+		return nil, errors.New("--end is in the future, only windows that have been streamed have VOD URLs")
+	}
+	return w, nil
+}
+
+// parseWindowTime accepts an epoch time in seconds, an RFC 3339 time, or a
+// duration like 2h meaning that long ago.
+func parseWindowTime(value string, now time.Time) (int, error) {
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < minEpoch {
+			return 0, fmt.Errorf("%q is not an epoch time in seconds", value)
+		}
+		return n, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return int(t.Unix()), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("expected an epoch time, an RFC 3339 time or a duration ago like 2h, got %q", value)
+	}
+	return int(now.Add(-d).Unix()), nil
+}
+
+// apply returns the part of every session inside the window, the live session
+// included as far as it has been streamed.
+func (w *channelWindow) apply(sessions *brightcove.Sessions) (*brightcove.Sessions, error) {
+	cut := &brightcove.Sessions{}
+	for _, session := range sessions.Events {
+		end := session.EndTime
+		if end == 0 {
+			end = w.end
+		}
+		start, end := max(session.StartTime, w.start), min(end, w.end)
+		if start >= end {
+			continue
+		}
+		session.StartTime, session.EndTime = start, end
+		cut.Events = append(cut.Events, session)
+	}
+
+	if len(cut.Events) == 0 {
+		return nil, fmt.Errorf("no session streamed between %s and %s", formatEpoch(w.start), formatEpoch(w.end))
+	}
+	return cut, nil
+}
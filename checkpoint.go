@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// checkpoint records the playback URLs of a batch as they complete, one JSON
+// line per URL, so an interrupted run can pick up where it left off with
+// --resume instead of spending its API calls again.
+type checkpoint struct {
+	mu   sync.Mutex
+	f    *os.File
+	done map[string]inputResult
+	// partial is set when the file ends with a line cut short.
+	partial bool
+}
+
+// openCheckpoint creates the checkpoint file at path or, with resume, loads
+// the URLs it records as completed and appends to it.
+func openCheckpoint(path string, resume bool) (*checkpoint, error) {
+	c := &checkpoint{done: map[string]inputResult{}}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		if err := c.load(path); err != nil {
+			return nil, err
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	f, err := os.OpenFile(path, flags, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening checkpoint: %w", err)
+	}
+	c.f = f
+	if c.partial {
+		// Keep the next line apart from the one cut short
+		if _, err := f.Write([]byte("\n")); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("error writing checkpoint: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// load reads the completed URLs of a checkpoint file. A missing file resumes
+// nothing, and a line cut short by a crash is ignored.
+func (c *checkpoint) load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading checkpoint: %w", err)
+	}
+
+	c.partial = len(data) > 0 && data[len(data)-1] != '\n'
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var result inputResult
+		if err := json.Unmarshal(line, &result); err != nil {
+			slog.Warn("ignoring invalid checkpoint line", "path", path, "line", i+1, "err", err)
+			continue
+		}
+		c.done[result.PlaybackURL] = result
+	}
+	slog.Info("resuming batch", "checkpoint", path, "completed", len(c.done))
+	return nil
+}
+
+// completed returns the recorded result of playbackURL, unless it wasn't
+// completed or one of its playback tokens expired since.
+func (c *checkpoint) completed(playbackURL string) (inputResult, bool) {
+	if c == nil {
+		return inputResult{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, ok := c.done[playbackURL]
+	if !ok {
+		return inputResult{}, false
+	}
+	now := time.Now()
+	for _, session := range result.Sessions {
+		for _, url := range session.URLs {
+			if !url.ExpiresAt.IsZero() && url.ExpiresAt.Before(now) {
+				return inputResult{}, false
+			}
+		}
+	}
+	return result, true
+}
+
+// record appends a completed URL to the checkpoint and syncs it to disk.
+func (c *checkpoint) record(result inputResult) error {
+	if c == nil {
+		return nil
+	}
+	line, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+	return c.f.Sync()
+}
+
+func (c *checkpoint) Close() error {
+	return c.f.Close()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// chunkSessions splits every ended session longer than size into consecutive
+// sessions of size, the last one holding the remainder, so each gets VOD URLs
+// of its own. Chunks are identified as <session ID>-part<N>, counting from 1,
+// and keep the end of the session as their BroadcastEndTime: the VOD window of
+// every chunk is that of the session. A zero size splits nothing.
+func chunkSessions(sessions *brightcove.Sessions, size time.Duration) *brightcove.Sessions {
+	seconds := int(size / time.Second)
+	if seconds <= 0 {
+		return sessions
+	}
+
+	chunked := &brightcove.Sessions{}
+	for _, session := range sessions.Events {
+		if session.EndTime == 0 || session.EndTime-session.StartTime <= seconds {
+			chunked.Events = append(chunked.Events, session)
+			continue
+		}
+		for part, start := 1, session.StartTime; start < session.EndTime; part, start = part+1, start+seconds {
+			chunk := session
+			chunk.ID = fmt.Sprintf("%s-part%d", session.ID, part)
+			chunk.StartTime = start
+			chunk.EndTime = min(start+seconds, session.EndTime)
+			chunk.BroadcastEndTime = cmp.Or(session.BroadcastEndTime, session.EndTime)
+			chunked.Events = append(chunked.Events, chunk)
+		}
+	}
+	return chunked
+}
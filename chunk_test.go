@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+func TestChunkSessions(t *testing.T) {
+	const start = 1_700_000_000
+	sessions := &brightcove.Sessions{Events: []brightcove.Session{
+		{ID: "long", StartTime: start, EndTime: start + 9000},
+		{ID: "short", StartTime: start + 10000, EndTime: start + 11000},
+		{ID: "live", StartTime: start + 12000},
+	}}
+
+	tests := []struct {
+		name string
+		size time.Duration
+		want []brightcove.Session
+	}{
+		{name: "no size", want: sessions.Events},
+		{name: "longer than every session", size: 3 * time.Hour, want: sessions.Events},
+		{name: "as long as the session", size: 150 * time.Minute, want: sessions.Events},
+		{
+			name: "hours",
+			size: time.Hour,
+			want: []brightcove.Session{
+				{ID: "long-part1", StartTime: start, EndTime: start + 3600, BroadcastEndTime: start + 9000},
+				{ID: "long-part2", StartTime: start + 3600, EndTime: start + 7200, BroadcastEndTime: start + 9000},
+				{ID: "long-part3", StartTime: start + 7200, EndTime: start + 9000, BroadcastEndTime: start + 9000},
+				{ID: "short", StartTime: start + 10000, EndTime: start + 11000},
+				{ID: "live", StartTime: start + 12000},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunked := chunkSessions(sessions, tt.size)
+			if !slices.Equal(chunked.Events, tt.want) {
+				t.Errorf("got %+v, want %+v", chunked.Events, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkVODWindow(t *testing.T) {
+	const vodWindowDays = 14
+	end := time.Now().AddDate(0, 0, 1-vodWindowDays)
+	session := brightcove.Session{ID: "long", StartTime: int(end.AddDate(0, 0, -2).Unix()), EndTime: int(end.Unix())}
+
+	// The first chunk ends outside the VOD window, the session doesn't
+	chunked := chunkSessions(&brightcove.Sessions{Events: []brightcove.Session{session}}, 24*time.Hour)
+	kept, dropped, err := skipExpiring(context.Background(), chunked, vodWindowDays, 12*time.Hour)
+	if err != nil || dropped != 0 {
+		t.Fatalf("got %d sessions dropped and error %v, want every chunk kept", dropped, err)
+	}
+	if len(kept.Events) != 2 {
+		t.Fatalf("got %+v, want 2 chunks", kept.Events)
+	}
+	for _, chunk := range kept.Events {
+		if !chunk.WithinVODWindow(vodWindowDays) {
+			t.Errorf("chunk %s outside the VOD window", chunk.ID)
+		}
+		if got, want := chunk.VODExpiry(vodWindowDays), session.VODExpiry(vodWindowDays); !got.Equal(want) {
+			t.Errorf("chunk %s expires %s, want %s", chunk.ID, got, want)
+		}
+	}
+}
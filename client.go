@@ -0,0 +1,476 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/secrets"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+	"github.com/zalando/go-keyring"
+)
+
+// userAgent identifies the CLI to the Brightcove APIs.
+const userAgent = "bc-vod-urls"
+
+// clientFlags are the flags shared by every command talking to Brightcove.
+type clientFlags struct {
+	timeout        time.Duration
+	retryAttempts  int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	rateLimit      float64
+	rateBurst      int
+	vodWindowDays  int
+	maxSessions    int
+	maxResponseMB  int
+	tokenTTL       time.Duration
+	noCache        bool
+	logLevel       string
+	logFormat      string
+	quiet          bool
+	configPath     string
+	profileName    string
+	secretSource   string
+	proxy          string
+	caFile         string
+	tlsCert        string
+	tlsKey         string
+	insecure       bool
+	tlsMinVersion  string
+	region         string
+	adConfigID     string
+	urlParams      keyValueList
+	endpoints      brightcove.BaseURLs
+
+	// playbackJWT is a pre-signed playback authorization JWT, playbackJWTKey
+	// the private key to sign one per session with instead.
+	playbackJWT       string
+	playbackJWTKey    string
+	playbackJWTClaims keyValueList
+	playbackJWTTTL    time.Duration
+
+	// config is the config file, loaded by parse.
+	config *config
+	// profile is the config file profile selected by newClient.
+	profile profile
+	// tlsConfig is built from the TLS flags by validate.
+	tlsConfig *tls.Config
+}
+
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	defaultRetry := brightcove.DefaultRetryPolicy()
+
+	fs.DurationVar(&f.timeout, "timeout", 0, "overall deadline for the run, e.g. 30s or 5m (0 means no deadline)")
+	fs.IntVar(&f.retryAttempts, "retry-attempts", envInt("RETRY_MAX_ATTEMPTS", defaultRetry.MaxAttempts), "attempts per API call before giving up, 1 disables retries (env RETRY_MAX_ATTEMPTS)")
+	fs.DurationVar(&f.retryBaseDelay, "retry-base-delay", envDuration("RETRY_BASE_DELAY", defaultRetry.BaseDelay), "backoff before the first retry, doubled on every retry (env RETRY_BASE_DELAY)")
+	fs.DurationVar(&f.retryMaxDelay, "retry-max-delay", envDuration("RETRY_MAX_DELAY", defaultRetry.MaxDelay), "maximum backoff between retries (env RETRY_MAX_DELAY)")
+	fs.Float64Var(&f.rateLimit, "rate-limit", envFloat("RATE_LIMIT", 0), "maximum Brightcove API requests per second, 0 means unlimited (env RATE_LIMIT)")
+	fs.IntVar(&f.rateBurst, "rate-burst", envInt("RATE_BURST", 1), "number of requests allowed to exceed --rate-limit in a burst (env RATE_BURST)")
+	fs.IntVar(&f.vodWindowDays, "vod-window-days", envInt("VOD_WINDOW_DAYS", brightcove.VODWindowDuration), "days after a session ends during which VOD URLs are generated for it (env VOD_WINDOW_DAYS)")
+	fs.IntVar(&f.maxSessions, "max-sessions", envInt("MAX_SESSIONS", 0), "stop fetching pages of a resource's sessions after this many, 0 means all (env MAX_SESSIONS)")
+	fs.IntVar(&f.maxResponseMB, "max-response-mb", envInt("MAX_RESPONSE_MB", brightcove.DefaultMaxResponseBytes>>20), "fail API calls whose response is larger than this many MiB (env MAX_RESPONSE_MB)")
+	fs.DurationVar(&f.tokenTTL, "token-ttl", envDuration("TOKEN_TTL", 0), "lifetime requested for playback tokens, e.g. 24h, 0 leaves it to the API (env TOKEN_TTL)")
+	fs.BoolVar(&f.noCache, "no-cache", false, "always request a new access token instead of reusing the cached one")
+	fs.StringVar(&f.logLevel, "log-level", envString("LOG_LEVEL", "info"), "minimum level of logged diagnostics: debug, info, warn or error (env LOG_LEVEL)")
+	fs.StringVar(&f.logFormat, "log-format", envString("LOG_FORMAT", "text"), "format of logged diagnostics: text or json (env LOG_FORMAT)")
+	fs.BoolVar(&f.quiet, "quiet", false, "only log errors and print nothing but the results, one VOD URL per line")
+	fs.BoolVar(&f.quiet, "q", false, "shorthand for --quiet")
+	fs.StringVar(&f.configPath, "config", envString("CONFIG_FILE", defaultConfigPath), "config file with credential profiles (env CONFIG_FILE)")
+	fs.StringVar(&f.proxy, "proxy", "", "proxy URL for every outgoing request, http(s)://host:port or socks5://[user:password@]host:port, overriding HTTP_PROXY, HTTPS_PROXY and NO_PROXY")
+	fs.StringVar(&f.caFile, "ca-file", envString("CA_FILE", ""), "PEM file of root CAs to trust in addition to the system ones, e.g. of a TLS-intercepting proxy (env CA_FILE)")
+	fs.StringVar(&f.tlsCert, "tls-cert", envString("TLS_CERT_FILE", ""), "PEM file of the client certificate presented to servers asking for one, e.g. an egress gateway requiring mutual TLS, with --tls-key (env TLS_CERT_FILE)")
+	fs.StringVar(&f.tlsKey, "tls-key", envString("TLS_KEY_FILE", ""), "PEM file of the private key of --tls-cert (env TLS_KEY_FILE)")
+	fs.BoolVar(&f.insecure, "insecure-skip-verify", false, "do not verify TLS certificates, only for debugging")
+	fs.StringVar(&f.tlsMinVersion, "tls-min-version", envString("TLS_MIN_VERSION", "1.2"), "minimum TLS version: 1.2 or 1.3 (env TLS_MIN_VERSION)")
+	fs.StringVar(&f.secretSource, "secret-source", envString("SECRET_SOURCE", ""), "fetch the credentials from vault:<path> or aws-sm:<secret-id> instead, re-read whenever a new access token is needed (env SECRET_SOURCE)")
+	fs.StringVar(&f.adConfigID, "ad-config-id", envString("AD_CONFIG_ID", ""), "server-side ad insertion configuration the VOD URLs play with, defaults to the profile's ad_config_id (env AD_CONFIG_ID)")
+	fs.Var(&f.urlParams, "url-param", "query parameter key=value appended to every generated VOD URL, e.g. for analytics tags, repeatable")
+	fs.StringVar(&f.playbackJWT, "playback-jwt", envString("PLAYBACK_JWT", ""), "pre-signed playback authorization JWT for accounts enforcing Playback Rights (env PLAYBACK_JWT)")
+	fs.StringVar(&f.playbackJWTKey, "playback-jwt-key", envString("PLAYBACK_JWT_KEY", ""), "PEM file of the RSA private key to sign a playback authorization JWT per session with, defaults to the profile's playback_jwt_key (env PLAYBACK_JWT_KEY)")
+	fs.Var(&f.playbackJWTClaims, "playback-jwt-claim", "claim added to the signed JWTs as key=value, a JSON value such as 2 or true is added as is, repeatable")
+	fs.DurationVar(&f.playbackJWTTTL, "playback-jwt-ttl", envDuration("PLAYBACK_JWT_TTL", brightcove.DefaultPlaybackJWTTTL), "how long the signed JWTs stay valid (env PLAYBACK_JWT_TTL)")
+	fs.StringVar(&f.region, "region", envString("BRIGHTCOVE_REGION", ""), "region of live resources whose playback URL isn't given, e.g. ap-south-1, defaults to the profile's region (env BRIGHTCOVE_REGION)")
+	fs.StringVar(&f.endpoints.OAuth, "oauth-url", envString("BRIGHTCOVE_OAUTH_URL", ""), "root of the OAuth API (env BRIGHTCOVE_OAUTH_URL, default "+brightcove.DefaultOAuthURL+")")
+	fs.StringVar(&f.endpoints.Live, "live-api-url", envString("BRIGHTCOVE_LIVE_API_URL", ""), "root of the Live API (env BRIGHTCOVE_LIVE_API_URL, default "+brightcove.DefaultLiveURL+")")
+	f.endpoints.LiveFallbacks = splitList(envString("BRIGHTCOVE_LIVE_API_FALLBACK_URLS", ""))
+	fs.Var((*commaList)(&f.endpoints.LiveFallbacks), "live-api-fallback-urls", "comma separated roots of the Live API to fail over to, in order, when it can't be reached (env BRIGHTCOVE_LIVE_API_FALLBACK_URLS)")
+	fs.StringVar(&f.endpoints.RegionalLive, "regional-live-api-url", envString("BRIGHTCOVE_REGIONAL_LIVE_API_URL", ""), "root of the Live API for resources of a known region, with {region} replaced by it (env BRIGHTCOVE_REGIONAL_LIVE_API_URL)")
+	fs.StringVar(&f.endpoints.Playback, "playback-api-url", envString("BRIGHTCOVE_PLAYBACK_API_URL", ""), "root of the playback URL lookup, defaults to the Live API (env BRIGHTCOVE_PLAYBACK_API_URL)")
+	fs.StringVar(&f.endpoints.CMS, "cms-api-url", envString("BRIGHTCOVE_CMS_API_URL", ""), "root of the CMS API (env BRIGHTCOVE_CMS_API_URL, default "+brightcove.DefaultCMSURL+")")
+	fs.StringVar(&f.endpoints.Ingest, "ingest-api-url", envString("BRIGHTCOVE_INGEST_API_URL", ""), "root of the Dynamic Ingest API (env BRIGHTCOVE_INGEST_API_URL, default "+brightcove.DefaultIngestURL+")")
+	fs.StringVar(&f.profileName, "profile", envString("BRIGHTCOVE_PROFILE", ""), "config file profile to take the credentials and default account from (env BRIGHTCOVE_PROFILE)")
+}
+
+// parse parses the command line, then fills in defaults from the config file
+// for the flags neither given nor set through their environment variable.
+func (f *clientFlags) parse(fs *flag.FlagSet, args []string) error {
+	fs.Parse(args)
+
+	cfg, err := loadConfig(f.configPath, f.configPath != defaultConfigPath)
+	if err != nil {
+		return err
+	}
+	f.config = cfg
+	cfg.Endpoints.apply(&f.endpoints)
+
+	return cfg.Defaults.apply(fs)
+}
+
+// setupLogging installs the default slog logger according to the flags.
+// Diagnostics always go to stderr so they never mix with the results.
+func (f *clientFlags) setupLogging() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(f.logLevel)); err != nil {
+		return fmt.Errorf("unsupported log level %q, expected debug, info, warn or error", f.logLevel)
+	}
+	if f.quiet {
+		level = slog.LevelError
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: brightcove.RedactAttr,
+	}
+
+	var handler slog.Handler
+	switch f.logFormat {
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("unsupported log format %q, expected text or json", f.logFormat)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+func (f *clientFlags) validate() error {
+	if f.vodWindowDays < 1 {
+		return fmt.Errorf("invalid VOD window of %d days, must be at least 1", f.vodWindowDays)
+	}
+	if f.maxSessions < 0 {
+		return fmt.Errorf("invalid --max-sessions %d, must not be negative", f.maxSessions)
+	}
+	if f.maxResponseMB < 1 {
+		return fmt.Errorf("invalid --max-response-mb %d, must be at least 1", f.maxResponseMB)
+	}
+	if f.tokenTTL < 0 || f.tokenTTL > 0 && f.tokenTTL < time.Second {
+		return fmt.Errorf("invalid --token-ttl %s, must be at least 1s", f.tokenTTL)
+	}
+	for key := range f.urlParams {
+		if key == "pt" || key == "bcov_auth" || key == "ad_config_id" {
+			return fmt.Errorf("invalid --url-param %s, the parameter is set by the tool", key)
+		}
+	}
+	if f.playbackJWT != "" && f.playbackJWTKey != "" {
+		return errors.New("--playback-jwt and --playback-jwt-key are mutually exclusive")
+	}
+	if f.playbackJWTTTL < time.Second {
+		return fmt.Errorf("invalid --playback-jwt-ttl %s, must be at least 1s", f.playbackJWTTTL)
+	}
+	roots := []struct{ name, url string }{
+		{"--oauth-url", f.endpoints.OAuth},
+		{"--live-api-url", f.endpoints.Live},
+		{"--regional-live-api-url", f.endpoints.RegionalLive},
+		{"--playback-api-url", f.endpoints.Playback},
+		{"--cms-api-url", f.endpoints.CMS},
+		{"--ingest-api-url", f.endpoints.Ingest},
+	}
+	for _, fallback := range f.endpoints.LiveFallbacks {
+		roots = append(roots, struct{ name, url string }{"--live-api-fallback-urls", fallback})
+	}
+	for _, root := range roots {
+		if root.url == "" {
+			continue
+		}
+		u, err := url.Parse(root.url)
+		if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid %s %q, expected an http(s) URL", root.name, root.url)
+		}
+	}
+	if f.proxy != "" {
+		u, err := url.Parse(f.proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("invalid proxy URL, expected e.g. http://proxy.corp:3128 or socks5://jump.corp:1080")
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("unsupported proxy scheme %q of %s, expected http, https, socks5 or socks5h", u.Scheme, u.Redacted())
+		}
+	}
+
+	tlsConfig, err := f.buildTLSConfig()
+	if err != nil {
+		return err
+	}
+	f.tlsConfig = tlsConfig
+	return nil
+}
+
+// buildTLSConfig returns the TLS configuration of outgoing requests.
+func (f *clientFlags) buildTLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{}
+
+	switch f.tlsMinVersion {
+	case "1.2":
+		cfg.MinVersion = tls.VersionTLS12
+	case "1.3":
+		cfg.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported TLS version %q, expected 1.2 or 1.3", f.tlsMinVersion)
+	}
+
+	if f.caFile != "" {
+		pem, err := os.ReadFile(expandHome(f.caFile))
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", f.caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (f.tlsCert == "") != (f.tlsKey == "") {
+		return nil, errors.New("--tls-cert and --tls-key must be given together")
+	}
+	if f.tlsCert != "" {
+		cert, err := tls.LoadX509KeyPair(expandHome(f.tlsCert), expandHome(f.tlsKey))
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if f.insecure {
+		slog.Warn("TLS CERTIFICATE VERIFICATION IS DISABLED, credentials and tokens can be intercepted. Never use --insecure-skip-verify in production")
+		cfg.InsecureSkipVerify = true
+	}
+
+	return cfg, nil
+}
+
+// context returns the context of the run, cancelled on SIGINT or SIGTERM or
+// once the --timeout deadline passes.
+func (f *clientFlags) context() (context.Context, context.CancelFunc) {
+	ctx, stop := signalContext()
+	if f.timeout <= 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// keychainCredentials fills in the credentials from the OS keychain entry of
+// the selected profile. The keychain is optional, when it is unavailable the
+// given credentials are returned unchanged.
+func (f *clientFlags) keychainCredentials(clientID, clientSecret string) (string, string) {
+	name := f.profileName
+	if name == "" {
+		name = defaultProfile
+	}
+
+	creds, err := keychainGet(name)
+	if err != nil {
+		if !errors.Is(err, keyring.ErrNotFound) {
+			slog.Debug("keychain unavailable", "err", err)
+		}
+		return clientID, clientSecret
+	}
+
+	if clientID == "" {
+		clientID = creds.ClientID
+	}
+	if clientSecret == "" && clientID == creds.ClientID {
+		clientSecret = creds.ClientSecret
+	}
+	return clientID, clientSecret
+}
+
+// playbackAuthorizer returns what authorizes the VOD URLs of accounts
+// enforcing Playback Rights: the --playback-jwt, a signer of the
+// --playback-jwt-key or the profile's key, or nil.
+func (f *clientFlags) playbackAuthorizer() (brightcove.PlaybackAuthorizer, error) {
+	if f.playbackJWT != "" {
+		return brightcove.StaticPlaybackJWT(f.playbackJWT), nil
+	}
+
+	path := f.playbackJWTKey
+	if path == "" {
+		path = f.profile.PlaybackJWTKey
+	}
+	if path == "" {
+		if len(f.playbackJWTClaims) > 0 {
+			return nil, errors.New("--playback-jwt-claim requires --playback-jwt-key")
+		}
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("error reading playback JWT key: %w", err)
+	}
+	key, err := brightcove.ParseRSAPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid playback JWT key %s: %w", path, err)
+	}
+
+	claims := map[string]any{}
+	for k, v := range f.playbackJWTClaims {
+		var value any
+		if json.Unmarshal([]byte(v), &value) != nil {
+			value = v
+		}
+		claims[k] = value
+	}
+	return &brightcove.PlaybackJWTSigner{Key: key, TTL: f.playbackJWTTTL, Claims: claims}, nil
+}
+
+// Timeouts of the media client, which has no deadline for the whole request.
+const (
+	mediaDialTimeout           = 10 * time.Second
+	mediaResponseHeaderTimeout = 30 * time.Second
+)
+
+// httpClient returns the HTTP client of API calls and webhooks, whose
+// requests and responses are small enough to have a deadline.
+func (f *clientFlags) httpClient() *http.Client {
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: f.transport(),
+	}
+}
+
+// mediaClient returns the HTTP client fetching manifests and segments from the
+// CDN. Only connecting and waiting for the response headers time out, as a
+// large segment on a slow link takes however long it takes; the run's context
+// cancels transfers.
+func (f *clientFlags) mediaClient() *http.Client {
+	transport := f.transport()
+	transport.DialContext = (&net.Dialer{Timeout: mediaDialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = mediaDialTimeout
+	transport.ResponseHeaderTimeout = mediaResponseHeaderTimeout
+	return &http.Client{Transport: transport}
+}
+
+// transport returns the transport of outgoing requests, honoring --proxy and
+// the TLS flags.
+func (f *clientFlags) transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if f.tlsConfig != nil {
+		transport.TLSClientConfig = f.tlsConfig
+	}
+	if f.proxy != "" {
+		// validate made sure the URL parses
+		proxyURL, _ := url.Parse(f.proxy)
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return transport
+}
+
+// newClient builds a Brightcove client from the flags and the credentials of
+// the --secret-source, the selected --profile or, without one, the CLIENT_ID
+// and CLIENT_SECRET environment variables, falling back to the default
+// profile.
+func (f *clientFlags) newClient() (*brightcove.Client, error) {
+	var provider brightcove.CredentialsProvider
+	var clientID, clientSecret string
+	if f.secretSource != "" {
+		var err error
+		if provider, err = secrets.Open(f.secretSource, f.httpClient()); err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		creds, err := provider.Credentials(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching client credentials: %w", err)
+		}
+		clientID, clientSecret = creds.ClientID, creds.ClientSecret
+	}
+
+	var err error
+	if f.profile, err = f.config.lookupProfile(f.profileName); err != nil {
+		return nil, err
+	}
+
+	if provider == nil {
+		clientID = os.Getenv("CLIENT_ID")
+		clientSecret = os.Getenv("CLIENT_SECRET")
+		if f.profileName != "" || clientID == "" && clientSecret == "" {
+			clientID, clientSecret = f.profile.ClientID, f.profile.ClientSecret
+		}
+		if clientID == "" || clientSecret == "" {
+			clientID, clientSecret = f.keychainCredentials(clientID, clientSecret)
+		}
+	}
+
+	if clientID == "" || clientSecret == "" {
+		return nil, errors.New("client credentials missing")
+	}
+
+	client := brightcove.NewClient(
+		brightcove.WithCredentials(clientID, clientSecret),
+		brightcove.WithCredentialsProvider(provider),
+		brightcove.WithHTTPClient(f.httpClient()),
+		brightcove.WithBaseURLs(f.endpoints),
+		brightcove.WithUserAgent(userAgent),
+		brightcove.WithRetryPolicy(brightcove.RetryPolicy{
+			MaxAttempts: f.retryAttempts,
+			BaseDelay:   f.retryBaseDelay,
+			MaxDelay:    f.retryMaxDelay,
+		}),
+	)
+	client.VODWindowDays = f.vodWindowDays
+	client.MaxSessions = f.maxSessions
+	client.MaxResponseBytes = int64(f.maxResponseMB) << 20
+	client.PlaybackTokenTTL = f.tokenTTL
+	client.Region = f.region
+	if client.Region == "" {
+		client.Region = f.profile.Region
+	}
+	client.AdConfigID = f.adConfigID
+	if client.AdConfigID == "" {
+		client.AdConfigID = f.profile.AdConfigID
+	}
+	client.URLParams = f.urlParams
+	if client.PlaybackAuth, err = f.playbackAuthorizer(); err != nil {
+		return nil, err
+	}
+	if f.rateLimit > 0 {
+		client.RateLimiter = brightcove.NewRateLimiter(f.rateLimit, f.rateBurst)
+	}
+	if !f.noCache {
+		if path, err := brightcove.DefaultTokenCachePath(); err != nil {
+			slog.Warn("token cache disabled", "err", err)
+		} else {
+			client.TokenCache = brightcove.FileTokenCache{Path: path}
+		}
+	}
+
+	return client, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// clipResult is the output record of a clip created from a session.
+type clipResult struct {
+	SessionID  string `json:"session_id"`
+	ResourceID string `json:"resource_id"`
+	AccountID  string `json:"account_id"`
+	StartTime  int    `json:"start_time"`
+	EndTime    int    `json:"end_time"`
+	ClipID     string `json:"clip_id"`
+	State      string `json:"state"`
+	VideoID    string `json:"video_id"`
+}
+
+func runClip(args []string) {
+	fs := flag.NewFlagSet("vodurls clip", flag.ExitOnError)
+	setUsage(fs, "Creates permanent Video Cloud clips of the sessions of a live resource.",
+		"./vodurls clip [--title TITLE] [--tags a,b] [--custom-field k=v] [--session ID | --session-index N] <PLAYBACK_URL>",
+		"./vodurls clip [flags] --account ID --resource ID",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	var rf resourceFlags
+	rf.register(fs)
+	output := fs.String("output", outputText, "output format (text or json)")
+	var vf videoFlags
+	vf.register(fs)
+	var sessionIDs stringList
+	fs.Var(&sessionIDs, "session", "only clip the session with this ID (repeatable)")
+	sessionIndex := -1
+	fs.Var((*indexFlag)(&sessionIndex), "session-index", "only clip the session at this 0-based position in the resource's session list")
+	latest := fs.Int("latest", 0, "only clip the N most recent ended sessions")
+	oldest := fs.Int("oldest", 0, "only clip the N earliest ended sessions")
+	trimStart := fs.String("trim-start", "", "cut this much from the start of each session (e.g. 90s or 2m), or start at this absolute epoch time")
+	trimEnd := fs.String("trim-end", "", "cut this much from the end of each session (e.g. 5m), or end at this absolute epoch time")
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() == 0 && !rf.set() {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if *output != outputText && *output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", *output)
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	selection := sessionSelection{ids: sessionIDs, index: sessionIndex, latest: *latest, oldest: *oldest}
+	if err := selection.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	trim, err := parseTrimRange(*trimStart, *trimEnd)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	accountID, resourceID, err := rf.resolve(ctx, client, cf.profile.AccountID, fs.Arg(0))
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	sessions, err := client.GetResourceSessions(ctx, accountID, resourceID)
+	if err != nil {
+		slog.Error("error getting sessions", "err", err)
+		exit(1)
+	}
+
+	if err := sessions.CheckNotLive(); err != nil {
+		slog.Error("error creating clip", "err", err)
+		exit(1)
+	}
+
+	if sessions, err = selection.apply(sessions); err != nil {
+		slog.Error("error selecting sessions", "err", err)
+		exit(1)
+	}
+	if sessions, err = trim.apply(sessions); err != nil {
+		slog.Error("error trimming sessions", "err", err)
+		exit(1)
+	}
+
+	var results []clipResult
+	for _, session := range sessions.Events {
+		if !session.WithinVODWindow(cf.vodWindowDays) {
+			slog.Info("skipping session outside the VOD window", "session_id", session.ID, "end_time", session.EndTime)
+			continue
+		}
+
+		clip, err := client.CreateClip(ctx, session, brightcove.ClipRequest{Video: vf.video("")})
+		if err != nil && clip != nil {
+			// The clip was created, only its metadata couldn't be written
+			slog.Warn("error writing clip metadata", "session_id", session.ID, "err", err)
+		} else if err != nil {
+			slog.Error("error creating clip", "session_id", session.ID, "err", err)
+			exit(1)
+		}
+
+		results = append(results, clipResult{
+			SessionID:  session.ID,
+			ResourceID: session.ResourceID,
+			AccountID:  session.AccountID,
+			StartTime:  session.StartTime,
+			EndTime:    session.EndTime,
+			ClipID:     clip.ID,
+			State:      clip.State,
+			VideoID:    clip.VideoID,
+		})
+	}
+
+	if len(results) == 0 {
+		slog.Error("no valid sessions to clip")
+		exit(1)
+	}
+
+	if *output == outputJSON {
+		err = writeJSON(os.Stdout, results)
+	} else {
+		err = writeClipsText(os.Stdout, results)
+	}
+	if err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+}
+
+func writeClipsText(w io.Writer, results []clipResult) error {
+	for i, result := range results {
+		videoID := result.VideoID
+		if videoID == "" {
+			videoID = "pending"
+		}
+		fmt.Fprintf(w, "\nCLIP[%d]: session %s, clip job %s, video ID %s\n", i, result.SessionID, result.ClipID, videoID)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
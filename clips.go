@@ -0,0 +1,307 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// clipInfo is the output record of the clips list command.
+type clipInfo struct {
+	ClipID     string `json:"clip_id"`
+	Label      string `json:"label"`
+	JobID      string `json:"job_id"`
+	ResourceID string `json:"resource_id"`
+	State      string `json:"state"`
+	VideoID    string `json:"video_id"`
+	StartTime  int    `json:"start_time"`
+	EndTime    int    `json:"end_time"`
+	// SessionID is the session the clip was cut from, when one of the
+	// resource's sessions holds it.
+	SessionID string `json:"session_id,omitempty"`
+}
+
+func runClips(args []string) {
+	if len(args) == 0 || args[0] != "list" && args[0] != "status" && args[0] != "delete" {
+		fmt.Fprintln(os.Stderr, "Usage: ./vodurls clips list [--output text|json] <PLAYBACK_URL>")
+		fmt.Fprintln(os.Stderr, "       ./vodurls clips list [--output text|json] --account ID --resource ID|--job-id ID")
+		fmt.Fprintln(os.Stderr, "       ./vodurls clips status [--account ID] [--output text|json] <CLIP_ID>...")
+		fmt.Fprintln(os.Stderr, "       ./vodurls clips delete [--account ID] <CLIP_ID>...")
+		exit(exitUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		runClipsList(args[1:])
+	default:
+		runClipsByID(args[0], args[1:])
+	}
+}
+
+func runClipsList(args []string) {
+	fs := flag.NewFlagSet("vodurls clips list", flag.ExitOnError)
+	setUsage(fs, "Lists the clip jobs already created from the live jobs of a resource, to avoid clipping a session twice. With --job-id only that job's clips are listed.",
+		"./vodurls clips list [--output text|json] <PLAYBACK_URL>",
+		"./vodurls clips list [--output text|json] --account ID --resource ID|--job-id ID",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	var rf resourceFlags
+	rf.register(fs)
+	output := fs.String("output", outputText, "output format (text or json)")
+	registerTZ(fs)
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() == 0 && !rf.set() {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if *output != outputText && *output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", *output)
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	accountID, resourceID, err := rf.resolve(ctx, client, cf.profile.AccountID, fs.Arg(0))
+	if err != nil {
+		slog.Error(err.Error())
+		exit(exitCode(err))
+	}
+
+	sessions, err := client.GetResourceSessions(ctx, accountID, resourceID)
+	if err != nil {
+		slog.Error("error getting sessions", "err", err)
+		exit(exitCode(err))
+	}
+
+	jobIDs := []string{rf.jobID}
+	if rf.jobID == "" {
+		jobIDs = sessionJobIDs(sessions)
+		if len(jobIDs) == 0 {
+			slog.Error("the sessions of the resource don't report their live job, pass --job-id", "resource_id", resourceID)
+			exit(1)
+		}
+	}
+
+	clips := []clipInfo{}
+	for _, jobID := range jobIDs {
+		jobClips, err := client.ListJobClips(ctx, accountID, jobID)
+		if err != nil {
+			slog.Error("error listing clips", "job_id", jobID, "err", err)
+			exit(exitCode(err))
+		}
+		for _, clip := range jobClips {
+			// A job can stream to other resources over its lifetime
+			if clip.ResourceID != "" && clip.ResourceID != resourceID {
+				continue
+			}
+			clips = append(clips, describeClip(clip, resourceID, sessions))
+		}
+	}
+	slices.SortStableFunc(clips, func(a, b clipInfo) int { return a.StartTime - b.StartTime })
+
+	if *output == outputJSON {
+		err = writeJSON(os.Stdout, clips)
+	} else {
+		err = writeClipsTable(os.Stdout, clips)
+	}
+	if err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+}
+
+// runClipsByID reports the state of, or deletes, the clip jobs given by ID.
+func runClipsByID(action string, args []string) {
+	fs := flag.NewFlagSet("vodurls clips "+action, flag.ExitOnError)
+	if action == "status" {
+		setUsage(fs, "Reports the state of clip jobs and the Video Cloud video they created.",
+			"./vodurls clips status [--account ID] [--output text|json] <CLIP_ID>...",
+		)
+	} else {
+		setUsage(fs, "Deletes clip jobs, cancelling the ones that haven't finished. The Video Cloud videos they created are kept.",
+			"./vodurls clips delete [--account ID] <CLIP_ID>...",
+		)
+	}
+	var cf clientFlags
+	cf.register(fs)
+	accountID := fs.String("account", envString("ACCOUNT_ID", ""), "account ID of the clip jobs, defaults to the profile's account_id (env ACCOUNT_ID)")
+	output := outputText
+	if action == "status" {
+		fs.StringVar(&output, "output", outputText, "output format (text or json)")
+		registerTZ(fs)
+	}
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if output != outputText && output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", output)
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if *accountID == "" {
+		*accountID = cf.profile.AccountID
+	}
+	if *accountID == "" {
+		slog.Error("clips " + action + " requires --account")
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	clips := []clipInfo{}
+	var failed int
+	var lastErr error
+	for _, clipID := range fs.Args() {
+		if action == "delete" {
+			if err := client.DeleteClip(ctx, *accountID, clipID); err != nil {
+				slog.Error("error deleting clip", "clip_id", clipID, "err", err)
+				failed, lastErr = failed+1, err
+				continue
+			}
+			slog.Info("deleted clip", "clip_id", clipID)
+			continue
+		}
+
+		clip, err := client.GetClip(ctx, *accountID, clipID)
+		if err != nil {
+			slog.Error("error getting clip", "clip_id", clipID, "err", err)
+			failed, lastErr = failed+1, err
+			continue
+		}
+		clips = append(clips, describeClip(*clip, "", &brightcove.Sessions{}))
+	}
+
+	if action == "status" {
+		if output == outputJSON {
+			err = writeJSON(os.Stdout, clips)
+		} else {
+			err = writeClipsTable(os.Stdout, clips)
+		}
+		if err != nil {
+			slog.Error("error writing output", "err", err)
+			exit(1)
+		}
+	}
+
+	if failed == fs.NArg() {
+		exit(exitCode(lastErr))
+	} else if failed > 0 {
+		slog.Error("some clips failed", "failed", failed, "total", fs.NArg())
+		exit(exitPartial)
+	}
+}
+
+// sessionJobIDs returns the distinct live jobs the sessions report, in session
+// order.
+func sessionJobIDs(sessions *brightcove.Sessions) []string {
+	var jobIDs []string
+	for _, session := range sessions.Events {
+		if session.JobID != "" && !slices.Contains(jobIDs, session.JobID) {
+			jobIDs = append(jobIDs, session.JobID)
+		}
+	}
+	return jobIDs
+}
+
+// describeClip returns the output record of a clip, with the session of the
+// resource whose time range holds it.
+func describeClip(clip brightcove.Clip, resourceID string, sessions *brightcove.Sessions) clipInfo {
+	info := clipInfo{
+		ClipID:     clip.ID,
+		Label:      clip.Label,
+		JobID:      clip.JobID,
+		ResourceID: cmp.Or(clip.ResourceID, resourceID),
+		State:      clip.State,
+		VideoID:    clip.VideoID,
+		StartTime:  clip.StartTime,
+		EndTime:    clip.EndTime,
+	}
+	for _, session := range sessions.Events {
+		if clip.StartTime >= session.StartTime && (session.EndTime == 0 || clip.EndTime <= session.EndTime) {
+			info.SessionID = session.ID
+			break
+		}
+	}
+	return info
+}
+
+func writeClipsTable(w io.Writer, clips []clipInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CLIP ID\tLABEL\tSTATE\tVIDEO ID\tSESSION ID\tSTART\tEND")
+
+	for _, clip := range clips {
+		videoID := clip.VideoID
+		if videoID == "" {
+			videoID = "pending"
+		}
+		sessionID := clip.SessionID
+		if sessionID == "" {
+			sessionID = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			clip.ClipID,
+			clip.Label,
+			clip.State,
+			videoID,
+			sessionID,
+			formatEpoch(clip.StartTime),
+			formatEpoch(clip.EndTime),
+		)
+	}
+
+	return tw.Flush()
+}
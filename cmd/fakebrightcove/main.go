@@ -0,0 +1,73 @@
+// Command fakebrightcove serves the fake Brightcove API of
+// pkg/brightcovetest, seeded with the sessions of a resource, to try vodurls
+// locally without real credentials.
+//
+//	go run ./cmd/fakebrightcove --sessions 3
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcovetest"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8080", "address to listen on")
+	accountID := flag.String("account", "1234567890001", "account ID of the resource")
+	resourceID := flag.String("resource", "6384185469112", "ID of the live resource")
+	sessions := flag.Int("sessions", 3, "how many ended sessions of an hour the resource has, a day apart and the last one ended an hour ago")
+	expired := flag.Int("expired", 0, "how many more sessions ended outside the VOD window")
+	live := flag.Bool("live", false, "add a live session, making the resource live")
+	pageSize := flag.Int("page-size", 0, "sessions per page of the sessions list, all of them on a single page when 0")
+	flag.Parse()
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		slog.Error("error listening", "addr", *addr, "err", err)
+		os.Exit(1)
+	}
+
+	srv := brightcovetest.NewUnstartedServer()
+	srv.Listener.Close()
+	srv.Listener = l
+	srv.PageSize = *pageSize
+
+	now := time.Now().Unix()
+	add := func(id string, start, end int64) {
+		srv.AddSession(brightcove.Session{ID: id, AccountID: *accountID, ResourceID: *resourceID, StartTime: int(start), EndTime: int(end)})
+	}
+	for i := range *expired {
+		end := now - int64(30+*expired-i)*86400
+		add("expired-"+strconv.Itoa(i+1), end-3600, end)
+	}
+	for i := range *sessions {
+		end := now - 3600 - int64(*sessions-1-i)*86400
+		add("session-"+strconv.Itoa(i+1), end-3600, end)
+	}
+	if *live {
+		add("live", now-600, 0)
+	}
+
+	srv.Start()
+	defer srv.Close()
+
+	fmt.Printf(`Fake Brightcove API listening on %s, point vodurls at it with:
+
+export BRIGHTCOVE_OAUTH_URL=%[1]s BRIGHTCOVE_LIVE_API_URL=%[1]s
+export CLIENT_ID=%s CLIENT_SECRET=%s
+./vodurls --account %s --resource %s
+`, srv.URL, srv.ClientID, srv.ClientSecret, *accountID, *resourceID)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+const defaultConfigPath = "~/.config/bc-vod-urls/config.toml"
+
+// defaultProfile is used when no profile is selected and the environment
+// holds no credentials.
+const defaultProfile = "default"
+
+// config is the optional TOML config file.
+type config struct {
+	Defaults defaults           `toml:"defaults"`
+	Profiles map[string]profile `toml:"profiles"`
+	Email    emailConfig        `toml:"email"`
+	// Endpoints override the roots of the Brightcove APIs.
+	Endpoints endpointsConfig `toml:"endpoints"`
+}
+
+// endpointsConfig is the [endpoints] section of the config file, the defaults
+// of the API root flags.
+type endpointsConfig struct {
+	OAuth        string `toml:"oauth"`
+	Live         string `toml:"live"`
+	RegionalLive string `toml:"regional_live"`
+	Playback     string `toml:"playback"`
+	CMS          string `toml:"cms"`
+	Ingest       string `toml:"ingest"`
+	// LiveFallbacks are the Live API roots to fail over to, in order.
+	LiveFallbacks []string `toml:"live_fallbacks"`
+}
+
+// apply fills in the roots of urls that were neither given by flag nor by
+// environment variable.
+func (e endpointsConfig) apply(urls *brightcove.BaseURLs) {
+	for _, root := range []struct {
+		url   *string
+		value string
+	}{
+		{&urls.OAuth, e.OAuth},
+		{&urls.Live, e.Live},
+		{&urls.RegionalLive, e.RegionalLive},
+		{&urls.Playback, e.Playback},
+		{&urls.CMS, e.CMS},
+		{&urls.Ingest, e.Ingest},
+	} {
+		if *root.url == "" {
+			*root.url = root.value
+		}
+	}
+	if len(urls.LiveFallbacks) == 0 {
+		urls.LiveFallbacks = e.LiveFallbacks
+	}
+}
+
+// defaults are flag defaults, used when a flag is neither given nor set
+// through its environment variable.
+type defaults struct {
+	Format             string `toml:"format"`
+	Output             string `toml:"output"`
+	VODWindowDays      int    `toml:"vod_window_days"`
+	Concurrency        int    `toml:"concurrency"`
+	BatchConcurrency   int    `toml:"batch_concurrency"`
+	AccountConcurrency int    `toml:"account_concurrency"`
+	LogLevel           string `toml:"log_level"`
+	NotifySlack        string `toml:"notify_slack"`
+	Upload             string `toml:"upload"`
+}
+
+// apply sets the flags of fs that have a default, aren't given on the
+// command line and whose environment variable is unset.
+func (d defaults) apply(fs *flag.FlagSet) error {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	for _, def := range []struct {
+		flag, env, value string
+	}{
+		{"format", "", d.Format},
+		{"output", "", d.Output},
+		{"vod-window-days", "VOD_WINDOW_DAYS", itoa(d.VODWindowDays)},
+		{"concurrency", "CONCURRENCY", itoa(d.Concurrency)},
+		{"batch-concurrency", "BATCH_CONCURRENCY", itoa(d.BatchConcurrency)},
+		{"account-concurrency", "ACCOUNT_CONCURRENCY", itoa(d.AccountConcurrency)},
+		{"log-level", "LOG_LEVEL", d.LogLevel},
+		{"notify-slack", "SLACK_WEBHOOK_URL", d.NotifySlack},
+		{"upload", "UPLOAD_URL", d.Upload},
+	} {
+		if def.value == "" || given[def.flag] || fs.Lookup(def.flag) == nil {
+			continue
+		}
+		if def.env != "" && os.Getenv(def.env) != "" {
+			continue
+		}
+		if err := fs.Set(def.flag, def.value); err != nil {
+			return fmt.Errorf("invalid config file default for %s: %w", def.flag, err)
+		}
+	}
+	return nil
+}
+
+// itoa formats n, leaving 0 (unset) empty.
+func itoa(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// profile is a named set of credentials and defaults, one per Brightcove
+// account or environment the user works with.
+type profile struct {
+	ClientID     string `toml:"client_id"`
+	ClientSecret string `toml:"client_secret"`
+	// AccountID is the default of --account.
+	AccountID string `toml:"account_id"`
+	// Region is the Brightcove region of the account's live resources, e.g.
+	// ap-south-1, the default of --region.
+	Region string `toml:"region"`
+	// AdConfigID is the SSAI ad configuration of the account's VOD URLs, the
+	// default of --ad-config-id.
+	AdConfigID string `toml:"ad_config_id"`
+	// PlaybackJWTKey is the private key file the account's playback
+	// authorization JWTs are signed with, the default of --playback-jwt-key.
+	PlaybackJWTKey string `toml:"playback_jwt_key"`
+}
+
+// loadConfig reads the config file at path. A missing file is only an error
+// when required is set, otherwise an empty config is returned.
+func loadConfig(path string, required bool) (*config, error) {
+	var cfg config
+	_, err := toml.DecodeFile(expandHome(path), &cfg)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return &cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	return &cfg, nil
+}
+
+// lookupProfile returns the profile called name. Without a name the default
+// profile is returned if the file has one.
+func (c *config) lookupProfile(name string) (profile, error) {
+	if name == "" {
+		return c.Profiles[defaultProfile], nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return profile{}, fmt.Errorf("profile %q not found in config file", name)
+	}
+	return p, nil
+}
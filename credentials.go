@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+// keychainService is the service name credentials are stored under in the
+// macOS Keychain, Windows Credential Manager or the Secret Service (libsecret).
+const keychainService = "bc-vod-urls"
+
+// keychainCredentials is the secret stored per profile in the OS keychain.
+type keychainCredentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// keychainGet loads the credentials of a profile from the OS keychain.
+func keychainGet(profileName string) (keychainCredentials, error) {
+	secret, err := keyring.Get(keychainService, profileName)
+	if err != nil {
+		return keychainCredentials{}, err
+	}
+
+	var creds keychainCredentials
+	if err := json.Unmarshal([]byte(secret), &creds); err != nil {
+		return keychainCredentials{}, fmt.Errorf("error decoding keychain entry: %w", err)
+	}
+	return creds, nil
+}
+
+func keychainSet(profileName string, creds keychainCredentials) error {
+	secret, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+	return keyring.Set(keychainService, profileName, string(secret))
+}
+
+func runCredentials(args []string) {
+	if len(args) == 0 || args[0] != "set" && args[0] != "delete" {
+		fmt.Fprintln(os.Stderr, "Usage: ./vodurls credentials set [--profile NAME] [--client-id ID]")
+		fmt.Fprintln(os.Stderr, "       ./vodurls credentials delete [--profile NAME]")
+		exit(exitUsage)
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("vodurls credentials "+action, flag.ExitOnError)
+	setUsage(fs, "Stores or deletes client credentials in the OS keychain.",
+		"./vodurls credentials set [--profile NAME] [--client-id ID]",
+		"./vodurls credentials delete [--profile NAME]",
+	)
+	profileName := fs.String("profile", envString("BRIGHTCOVE_PROFILE", defaultProfile), "profile the credentials belong to (env BRIGHTCOVE_PROFILE)")
+	var clientID *string
+	if action == "set" {
+		clientID = fs.String("client-id", "", "client ID to store, prompted for when not given")
+	}
+	fs.Parse(args[1:])
+
+	if action == "delete" {
+		if err := keyring.Delete(keychainService, *profileName); err != nil {
+			slog.Error("error deleting credentials from the keychain", "profile", *profileName, "err", err)
+			exit(1)
+		}
+		slog.Info("deleted credentials from the keychain", "profile", *profileName)
+		return
+	}
+
+	creds, err := promptCredentials(*clientID)
+	if err != nil {
+		slog.Error("error reading credentials", "err", err)
+		exit(1)
+	}
+
+	if err := keychainSet(*profileName, creds); err != nil {
+		slog.Error("error storing credentials in the keychain", "profile", *profileName, "err", err)
+		exit(1)
+	}
+	slog.Info("stored credentials in the keychain", "profile", *profileName)
+}
+
+// promptCredentials reads the client ID, unless already known, and the client
+// secret from stdin. The secret is not echoed when stdin is a terminal.
+func promptCredentials(clientID string) (keychainCredentials, error) {
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	reader := bufio.NewReader(os.Stdin)
+
+	readLine := func() (string, error) {
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	if clientID == "" {
+		if interactive {
+			fmt.Fprint(os.Stderr, "Client ID: ")
+		}
+		var err error
+		if clientID, err = readLine(); err != nil {
+			return keychainCredentials{}, err
+		}
+	}
+
+	var clientSecret string
+	if interactive {
+		fmt.Fprint(os.Stderr, "Client secret: ")
+		secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return keychainCredentials{}, err
+		}
+		clientSecret = strings.TrimSpace(string(secret))
+	} else {
+		var err error
+		if clientSecret, err = readLine(); err != nil {
+			return keychainCredentials{}, err
+		}
+	}
+
+	if clientID == "" || clientSecret == "" {
+		return keychainCredentials{}, errors.New("client ID and secret must not be empty")
+	}
+	return keychainCredentials{ClientID: clientID, ClientSecret: clientSecret}, nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/hls"
+)
+
+const segmentAttempts = 3
+
+func runDownload(args []string) {
+	fs := flag.NewFlagSet("vodurls download", flag.ExitOnError)
+	setUsage(fs, "Downloads an HLS VOD URL into a local TS or MP4 file.",
+		"./vodurls download [-o vod.ts|vod.mp4] [--concurrency N] [--ffmpeg] <VOD_URL>",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	outputFile := fs.String("o", "vod.ts", "file to write, a .mp4 file is remuxed with ffmpeg")
+	concurrency := fs.Int("concurrency", 4, "number of segments downloaded in parallel")
+	useFFmpeg := fs.Bool("ffmpeg", false, "let ffmpeg download and mux the whole VOD instead of the built-in downloader")
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if *concurrency < 1 {
+		slog.Error("concurrency must be at least 1", "concurrency", *concurrency)
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	vodURL := fs.Arg(0)
+	remux := strings.EqualFold(filepath.Ext(*outputFile), ".mp4")
+
+	if *useFFmpeg || remux {
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			slog.Error("ffmpeg is required for --ffmpeg and .mp4 output, install it or write a .ts file", "err", err)
+			exit(1)
+		}
+	}
+
+	if *useFFmpeg {
+		if err := ffmpeg(ctx, vodURL, *outputFile); err != nil {
+			slog.Error("error downloading VOD with ffmpeg", "err", err)
+			exit(1)
+		}
+		slog.Info("downloaded VOD", "file", *outputFile)
+		return
+	}
+
+	target := *outputFile
+	if remux {
+		target = strings.TrimSuffix(*outputFile, filepath.Ext(*outputFile)) + ".ts.part"
+		defer os.Remove(target)
+	}
+
+	if err := downloadHLS(ctx, cf.mediaClient(), vodURL, target, *concurrency); err != nil {
+		slog.Error("error downloading VOD", "err", err)
+		exit(1)
+	}
+
+	if remux {
+		if err := ffmpeg(ctx, target, *outputFile); err != nil {
+			slog.Error("error remuxing VOD", "err", err)
+			exit(1)
+		}
+	}
+
+	slog.Info("downloaded VOD", "file", *outputFile)
+}
+
+// downloadHLS downloads the best rendition of an HLS VOD and concatenates its
+// segments into path.
+func downloadHLS(ctx context.Context, httpClient *http.Client, url, path string, concurrency int) error {
+	body, err := fetchManifest(ctx, httpClient, url)
+	if err != nil {
+		return err
+	}
+
+	mediaURL := url
+	if hls.IsMaster(body) {
+		master, err := hls.ParseMaster(body, url)
+		if err != nil {
+			return fmt.Errorf("error parsing master playlist: %w", err)
+		}
+		if len(master.Variants) == 0 {
+			return errors.New("master playlist has no variants")
+		}
+		best := slices.MaxFunc(master.Variants, func(a, b hls.Variant) int { return a.Bandwidth - b.Bandwidth })
+		mediaURL = best.URI
+		slog.InfoContext(ctx, "downloading rendition", "resolution", best.Resolution, "bandwidth", best.Bandwidth)
+
+		if body, err = fetchManifest(ctx, httpClient, mediaURL); err != nil {
+			return err
+		}
+	}
+
+	media, err := hls.ParseMedia(body, mediaURL)
+	if err != nil {
+		return fmt.Errorf("error parsing media playlist: %w", err)
+	}
+	if media.Encrypted {
+		return errors.New("encrypted HLS is not supported by the built-in downloader, use --ffmpeg")
+	}
+
+	uris := make([]string, 0, len(media.Segments)+1)
+	if media.Map != "" {
+		uris = append(uris, media.Map)
+	}
+	for _, s := range media.Segments {
+		uris = append(uris, s.URI)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := downloadSegments(ctx, httpClient, uris, f, concurrency); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// downloadSegments fetches up to concurrency segments in parallel and writes
+// them to w in playlist order. At most concurrency segments are held in
+// memory at a time.
+func downloadSegments(ctx context.Context, httpClient *http.Client, uris []string, w io.Writer, concurrency int) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	type segment struct {
+		data []byte
+		err  error
+	}
+
+	slots := make(chan struct{}, concurrency)
+	results := make([]chan segment, len(uris))
+	for i := range results {
+		results[i] = make(chan segment, 1)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i, uri := range uris {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				data, err := fetchSegment(ctx, httpClient, uri)
+				results[i] <- segment{data: data, err: err}
+			}()
+		}
+	}()
+	defer wg.Wait()
+
+	for i := range uris {
+		var s segment
+		select {
+		case s = <-results[i]:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+
+		if s.err != nil {
+			cancel(s.err)
+			return fmt.Errorf("error downloading segment %d: %w", i, s.err)
+		}
+		if _, err := w.Write(s.data); err != nil {
+			cancel(err)
+			return fmt.Errorf("error writing segment %d: %w", i, err)
+		}
+		<-slots
+
+		if (i+1)%100 == 0 {
+			slog.InfoContext(ctx, "downloading segments", "done", i+1, "total", len(uris))
+		}
+	}
+
+	return nil
+}
+
+func fetchSegment(ctx context.Context, httpClient *http.Client, uri string) ([]byte, error) {
+	var err error
+	for attempt := 1; attempt <= segmentAttempts; attempt++ {
+		var data []byte
+		if data, err = fetchBody(ctx, httpClient, uri, 0); err == nil {
+			return data, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		slog.DebugContext(ctx, "retrying segment", "uri", uri, "attempt", attempt, "err", err)
+	}
+	return nil, err
+}
+
+// ffmpeg copies the streams of input into output without re-encoding them.
+func ffmpeg(ctx context.Context, input, output string) error {
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-loglevel", "error", "-y", "-i", input, "-c", "copy", output)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
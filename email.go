@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emailConfig is the [email] section of the config file, the SMTP server and
+// distribution list the results are mailed to.
+type emailConfig struct {
+	Host     string   `toml:"host"`
+	Port     int      `toml:"port"`
+	Username string   `toml:"username"`
+	Password string   `toml:"password"`
+	From     string   `toml:"from"`
+	To       []string `toml:"to"`
+	// TLS is starttls (the default), tls for implicit TLS, usually on port
+	// 465, or none.
+	TLS string `toml:"tls"`
+}
+
+// emailNotifier mails the run summary and the VOD URLs.
+type emailNotifier struct {
+	config    emailConfig
+	tlsConfig *tls.Config
+}
+
+func newEmailNotifier(cfg emailConfig) (*emailNotifier, error) {
+	// A fresh slice: cfg.To shares its array with the config file's
+	// recipients, and a trailing comma of --notify-email leaves an empty one
+	var to []string
+	for _, addr := range cfg.To {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	cfg.To = to
+
+	if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return nil, errors.New("email notifications need an SMTP host, a from address and recipients")
+	}
+	if cfg.TLS == "" {
+		cfg.TLS = "starttls"
+	}
+	if cfg.TLS != "starttls" && cfg.TLS != "tls" && cfg.TLS != "none" {
+		return nil, fmt.Errorf("unsupported email tls %q, expected starttls, tls or none", cfg.TLS)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+		if cfg.TLS == "tls" {
+			cfg.Port = 465
+		}
+	}
+	for _, addr := range append([]string{cfg.From}, cfg.To...) {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return nil, fmt.Errorf("invalid email address %q: %w", addr, err)
+		}
+	}
+
+	return &emailNotifier{config: cfg, tlsConfig: &tls.Config{ServerName: cfg.Host}}, nil
+}
+
+func (n *emailNotifier) notify(ctx context.Context, batch batchResult) error {
+	message, err := n.message(batch)
+	if err != nil {
+		return err
+	}
+	if err := n.send(ctx, message); err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+	return nil
+}
+
+// message renders batch as a plain text email, quoted-printable encoded as
+// VOD URLs are longer than SMTP allows a line to be.
+func (n *emailNotifier) message(batch batchResult) ([]byte, error) {
+	var urls, failed int
+	for _, result := range batch {
+		for _, session := range result.Sessions {
+			urls += len(session.URLs)
+		}
+		if result.Error != "" {
+			failed++
+		}
+	}
+	subject := fmt.Sprintf("%d VOD URLs generated", urls)
+	if failed > 0 {
+		subject += fmt.Sprintf(", %d playback URLs failed", failed)
+	}
+
+	var text bytes.Buffer
+	fmt.Fprintf(&text, "%s.\n", subject)
+	if err := writeBatchText(&text, batch); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&msg)
+	if _, err := qp.Write(bytes.ReplaceAll(text.Bytes(), []byte("\n"), []byte("\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
+
+func (n *emailNotifier) send(ctx context.Context, message []byte) error {
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	// net/smtp doesn't take a context, bound the whole exchange instead
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Minute)
+	}
+	conn.SetDeadline(deadline)
+
+	if n.config.TLS == "tls" {
+		conn = tls.Client(conn, n.tlsConfig)
+	}
+	c, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if n.config.TLS == "starttls" {
+		if err := c.StartTLS(n.tlsConfig); err != nil {
+			return err
+		}
+	}
+	if n.config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(n.config.From); err != nil {
+		return err
+	}
+	for _, to := range n.config.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
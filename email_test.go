@@ -0,0 +1,34 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEmailRecipients(t *testing.T) {
+	tests := []struct {
+		name    string
+		to      []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "trimmed", to: []string{" a@example.com", "b@example.com "}, want: []string{"a@example.com", "b@example.com"}},
+		{name: "trailing comma", to: []string{"a@example.com", ""}, want: []string{"a@example.com"}},
+		{name: "blank", to: []string{" ", ""}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to := slices.Clone(tt.to)
+			n, err := newEmailNotifier(emailConfig{Host: "smtp.example.com", From: "vodurls@example.com", To: to})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if err == nil && !slices.Equal(n.config.To, tt.want) {
+				t.Errorf("got recipients %q, want %q", n.config.To, tt.want)
+			}
+			if !slices.Equal(to, tt.to) {
+				t.Errorf("recipients %q modified to %q", tt.to, to)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// embedTemplate is the HTML snippet of a single VOD URL. Each snippet loads
+// its player library itself, so it can be pasted into a page on its own.
+// Browsers playing HLS natively, i.e. Safari, skip hls.js.
+var embedTemplate = template.Must(template.New("embed").Parse(`<figure>
+<video id="{{.ID}}" controls playsinline preload="metadata" style="width:100%;max-width:960px;aspect-ratio:16/9;background:#000"></video>
+<figcaption>{{.Title}}</figcaption>
+</figure>
+{{- if .DASH}}
+<script src="https://cdn.dashjs.org/latest/dash.all.min.js"></script>
+<script>
+dashjs.MediaPlayer().create().initialize(document.getElementById({{.ID}}), {{.URL}}, false);
+</script>
+{{- else}}
+<script src="https://cdn.jsdelivr.net/npm/hls.js@1"></script>
+<script>
+(function () {
+  var video = document.getElementById({{.ID}});
+  if (video.canPlayType("application/vnd.apple.mpegurl")) {
+    video.src = {{.URL}};
+  } else if (Hls.isSupported()) {
+    var hls = new Hls();
+    hls.loadSource({{.URL}});
+    hls.attachMedia(video);
+  }
+})();
+</script>
+{{- end}}
+`))
+
+type embedData struct {
+	Title string
+	ID    string
+	URL   string
+	DASH  bool
+}
+
+// writeEmbed renders a ready-to-paste HTML player of every URL, separated by
+// blank lines.
+func writeEmbed(w io.Writer, results []sessionResult) error {
+	for _, result := range results {
+		for _, url := range result.URLs {
+			data := embedData{
+				Title: fmt.Sprintf("Session %s (%s)", result.SessionID, strings.ToUpper(url.Format)),
+				ID:    embedID(result.SessionID, url.Format),
+				URL:   url.URL,
+				DASH:  url.Format == brightcove.ManifestFormatDASH,
+			}
+			if result.StartTime != 0 {
+				data.Title = fmt.Sprintf("Session %s: %s to %s (%s)", result.SessionID, formatEpoch(result.StartTime), formatEpoch(result.EndTime), strings.ToUpper(url.Format))
+			}
+			if err := embedTemplate.Execute(w, data); err != nil {
+				return fmt.Errorf("error rendering embed snippet: %w", err)
+			}
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// embedID is the element ID of the player of a session's URL, unique within
+// a page holding the snippets of a run.
+func embedID(sessionID, format string) string {
+	id := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, sessionID)
+	return "vod-" + id + "-" + format
+}
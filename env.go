@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// defaultEnvFile is loaded, if it exists, when no --env-file is given.
+const defaultEnvFile = ".env"
+
+// loadEnvFiles loads the --env-file flags out of args, or ./.env if there are
+// none and it exists, into the environment, and returns args without them. It
+// runs before any command parses its flags, so the files can provide defaults
+// for them. Variables already set in the environment take precedence, as do
+// earlier files over later ones.
+func loadEnvFiles(args []string) ([]string, error) {
+	var files, rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "env-file" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, errors.New("flag needs an argument: --env-file")
+			}
+			i++
+			value = args[i]
+		}
+		files = append(files, expandHome(value))
+	}
+
+	if len(files) == 0 {
+		err := godotenv.Load(defaultEnvFile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("error loading %s: %w", defaultEnvFile, err)
+		}
+		return rest, nil
+	}
+
+	for _, file := range files {
+		if err := godotenv.Load(file); err != nil {
+			return nil, fmt.Errorf("error loading env file %s: %w", file, err)
+		}
+	}
+	return rest, nil
+}
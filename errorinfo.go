@@ -0,0 +1,96 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// errorInfo is a failure as reported in JSON output, so it can be handled
+// without parsing log lines.
+type errorInfo struct {
+	// Code classifies the failure, e.g. live_session_active or api_error.
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// SessionID and Format are set for the failures of a single session.
+	SessionID string `json:"session_id,omitempty"`
+	Format    string `json:"format,omitempty"`
+	// Status is the HTTP status of a failed API call, and APICode the
+	// Brightcove error code it reported.
+	Status  int    `json:"status,omitempty"`
+	APICode string `json:"api_code,omitempty"`
+}
+
+func newErrorInfo(err error) *errorInfo {
+	info := &errorInfo{Code: errorCode(err), Message: err.Error()}
+	var apiErr *brightcove.APIError
+	if errors.As(err, &apiErr) {
+		info.Status, info.APICode = apiErr.Status, apiErr.Code
+	}
+	var sessionErr *brightcove.SessionError
+	if errors.As(err, &sessionErr) {
+		info.SessionID, info.Format = sessionErr.Session.ID, sessionErr.Format
+	}
+	return info
+}
+
+// errorCode classifies err for errorInfo.Code.
+func errorCode(err error) string {
+	var apiErr *brightcove.APIError
+	switch {
+	case errors.Is(err, errSkipped):
+		return "skipped"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "cancelled"
+	case errors.Is(err, brightcove.ErrAuthentication):
+		return "authentication_failed"
+	case errors.Is(err, brightcove.ErrMalformedPlaybackURL):
+		return "malformed_playback_url"
+	case errors.Is(err, brightcove.ErrResourceNotFound):
+		return "resource_not_found"
+	case errors.Is(err, brightcove.ErrRedundantGroup):
+		return "redundant_group"
+	case errors.Is(err, brightcove.ErrLiveSessionActive):
+		return "live_session_active"
+	case errors.Is(err, brightcove.ErrNoSessions):
+		return "no_sessions"
+	case errors.Is(err, brightcove.ErrVODWindowExpired):
+		return "vod_window_expired"
+	case errors.Is(err, brightcove.ErrResponseTooLarge):
+		return "response_too_large"
+	case errors.Is(err, errNoRendition):
+		return "no_matching_rendition"
+	case errors.As(err, &apiErr):
+		return "api_error"
+	}
+	return "error"
+}
+
+// addSessionErrors adds the failures of single sessions to the results of
+// their session, adding a record without URLs for the sessions none of whose
+// URLs could be generated. Results stay sorted by start time.
+func addSessionErrors(results []sessionResult, failures []*brightcove.SessionError, vodWindowDays int) []sessionResult {
+	if len(failures) == 0 {
+		return results
+	}
+
+	for _, failure := range failures {
+		i := slices.IndexFunc(results, func(r sessionResult) bool { return r.SessionID == failure.Session.ID })
+		if i == -1 {
+			results = append(results, newSessionResult(failure.Session, vodWindowDays))
+			i = len(results) - 1
+		}
+		info := newErrorInfo(failure)
+		// The session is told by the record already
+		info.Message = failure.Err.Error()
+		results[i].Errors = append(results[i].Errors, *info)
+	}
+
+	slices.SortStableFunc(results, func(a, b sessionResult) int { return cmp.Compare(a.StartTime, b.StartTime) })
+	return results
+}
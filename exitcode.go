@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// Exit codes of VOD URL generation, so wrapper scripts can branch on the
+// kind of failure. They are documented in the README.
+const (
+	exitOK = 0
+	// exitFailure is any failure not covered by a more specific code.
+	exitFailure = 1
+	// exitUsage is a missing argument or unknown flag, as used by the flag
+	// package.
+	exitUsage = 2
+	// exitAuth means no access token could be obtained with the credentials.
+	exitAuth = 3
+	// exitMalformedURL means the playback URL could not be parsed.
+	exitMalformedURL = 4
+	// exitLive means the resource has an ongoing live session.
+	exitLive = 5
+	// exitExpired means every session ended outside the VOD window.
+	exitExpired = 6
+	// exitAPI means a Brightcove API call failed.
+	exitAPI = 7
+	// exitPartial means some VOD URLs were generated but others failed or
+	// did not pass --verify.
+	exitPartial = 8
+)
+
+// exitError attaches an exit code to an error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode makes the process exit with code if err ends the run, unless
+// it wraps one of the client's errors with a code of its own. The innermost
+// code wins when errors with codes are wrapped again.
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return err
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the exit code for an error ending the run.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, brightcove.ErrAuthentication):
+		return exitAuth
+	case errors.Is(err, brightcove.ErrMalformedPlaybackURL):
+		return exitMalformedURL
+	case errors.Is(err, brightcove.ErrLiveSessionActive):
+		return exitLive
+	case errors.Is(err, brightcove.ErrVODWindowExpired):
+		return exitExpired
+	}
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	var apiErr *brightcove.APIError
+	if errors.As(err, &apiErr) {
+		return exitAPI
+	}
+	return exitFailure
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/history"
+)
+
+// expiringSession is the output record of the expiring command.
+type expiringSession struct {
+	AccountID           string    `json:"account_id"`
+	ResourceID          string    `json:"resource_id"`
+	SessionID           string    `json:"session_id"`
+	StartTime           int       `json:"start_time"`
+	EndTime             int       `json:"end_time"`
+	VODExpiresAt        time.Time `json:"vod_expires_at"`
+	VODRemainingSeconds int       `json:"vod_remaining_seconds"`
+}
+
+func runExpiring(args []string) {
+	fs := flag.NewFlagSet("vodurls expiring", flag.ExitOnError)
+	setUsage(fs, "Reports the sessions whose VOD window ends soon, so they can be archived before they are gone. Resources are read from playback URLs, --input or, by default, the history database.",
+		"./vodurls expiring [--within 3d] [--db PATH]",
+		"./vodurls expiring [--within 3d] --input FILE",
+		"./vodurls expiring [--within 3d] <PLAYBACK_URL>...",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	within := dayDuration(3 * 24 * time.Hour)
+	fs.Var(&within, "within", "report sessions whose VOD window ends within this long, e.g. 3d or 36h")
+	input := fs.String("input", "", "file with one playback URL per line, or - for stdin")
+	db := fs.String("db", envString("HISTORY_DB", defaultHistoryDB), "history database whose resources are scanned when no playback URL or --input is given (env HISTORY_DB)")
+	output := fs.String("output", outputText, "output format (text or json)")
+	registerTZ(fs)
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if *output != outputText && *output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", *output)
+		exit(1)
+	}
+
+	if within <= 0 {
+		slog.Error("--within must be positive", "within", within.String())
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	var resources []history.Resource
+	if fs.NArg() > 0 || *input != "" {
+		playbackURLs := fs.Args()
+		if *input != "" {
+			inputs, err := readInputs(*input)
+			if err != nil {
+				slog.Error("error reading input", "err", err)
+				exit(1)
+			}
+			playbackURLs = append(playbackURLs, inputs...)
+		}
+		for _, playbackURL := range dedupeResources(playbackURLs) {
+			accountID, resourceID, err := client.ResolvePlaybackURL(playbackURL)
+			if err != nil {
+				slog.Error(err.Error(), "playback_url", playbackURL)
+				exit(exitMalformedURL)
+			}
+			resources = append(resources, history.Resource{AccountID: accountID, ResourceID: resourceID})
+		}
+	} else {
+		store, err := history.Open(expandHome(*db))
+		if err != nil {
+			slog.Error(err.Error())
+			exit(1)
+		}
+		resources, err = store.Resources(ctx)
+		store.Close()
+		if err != nil {
+			slog.Error(err.Error())
+			exit(1)
+		}
+		if len(resources) == 0 {
+			slog.Error("no resources in the history, pass playback URLs or --input", "db", *db)
+			exit(1)
+		}
+	}
+
+	// Authenticate up front so bad credentials fail before any other work
+	if _, err := client.AccessToken(ctx); err != nil {
+		slog.Error("error generating access token", "err", err)
+		exit(exitAuth)
+	}
+
+	now := time.Now()
+	deadline := now.Add(time.Duration(within))
+	expiring := []expiringSession{}
+	var failed int
+	for _, resource := range resources {
+		sessions, err := client.GetResourceSessions(ctx, resource.AccountID, resource.ResourceID)
+		if err != nil {
+			slog.Error("error getting sessions", "account_id", resource.AccountID, "resource_id", resource.ResourceID, "err", err)
+			failed++
+			continue
+		}
+		for _, session := range sessions.Events {
+			if session.EndTime == 0 {
+				continue
+			}
+			expiry := session.VODExpiry(client.VODWindowDays)
+			if expiry.Before(now) || !expiry.Before(deadline) {
+				continue
+			}
+			expiring = append(expiring, expiringSession{
+				AccountID:           session.AccountID,
+				ResourceID:          session.ResourceID,
+				SessionID:           session.ID,
+				StartTime:           session.StartTime,
+				EndTime:             session.EndTime,
+				VODExpiresAt:        expiry,
+				VODRemainingSeconds: int(expiry.Sub(now).Seconds()),
+			})
+		}
+	}
+	slices.SortStableFunc(expiring, func(a, b expiringSession) int { return a.VODExpiresAt.Compare(b.VODExpiresAt) })
+
+	if *output == outputJSON {
+		err = writeJSON(os.Stdout, expiring)
+	} else {
+		err = writeExpiringTable(os.Stdout, expiring)
+	}
+	if err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+
+	if failed == len(resources) {
+		slog.Error("every resource failed", "total", len(resources))
+		exit(exitAPI)
+	} else if failed > 0 {
+		slog.Error("some resources failed", "failed", failed, "total", len(resources))
+		exit(exitPartial)
+	}
+}
+
+func writeExpiringTable(w io.Writer, sessions []expiringSession) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESOURCE ID\tSESSION ID\tSTART\tEND\tVOD EXPIRES\tLEFT")
+
+	for _, s := range sessions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.ResourceID,
+			s.SessionID,
+			formatEpoch(s.StartTime),
+			formatEpoch(s.EndTime),
+			formatTime(s.VODExpiresAt),
+			formatDays(time.Duration(s.VODRemainingSeconds)*time.Second),
+		)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// stringList is a flag that can be given multiple times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// commaList is a flag holding a comma separated list, replaced as a whole
+// when given.
+type commaList []string
+
+func (l *commaList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *commaList) Set(value string) error {
+	*l = splitList(value)
+	return nil
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// keyValueList is a repeatable key=value flag.
+type keyValueList map[string]string
+
+func (l *keyValueList) String() string {
+	var pairs []string
+	for k, v := range *l {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (l *keyValueList) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	if *l == nil {
+		*l = keyValueList{}
+	}
+	(*l)[k] = v
+	return nil
+}
+
+// indexFlag is --session-index, a 0-based position or -1 for none. Negative
+// positions are rejected as a usage error rather than taken for none.
+type indexFlag int
+
+func (i *indexFlag) String() string {
+	return strconv.Itoa(int(*i))
+}
+
+func (i *indexFlag) Set(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return errors.New("expected a 0-based position")
+	}
+	if n < -1 {
+		return fmt.Errorf("expected a 0-based position, got %d", n)
+	}
+	*i = indexFlag(n)
+	return nil
+}
+
+// videoFlags set the metadata of Video Cloud videos created by clip and
+// archive.
+type videoFlags struct {
+	title        string
+	tags         string
+	customFields keyValueList
+}
+
+func (f *videoFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.title, "title", "", "name of the created Video Cloud video")
+	fs.StringVar(&f.title, "name", "", "alias of --title")
+	fs.StringVar(&f.tags, "tags", "", "comma separated tags of the created Video Cloud video")
+	fs.Var(&f.customFields, "custom-field", "custom field key=value of the created Video Cloud video (repeatable)")
+}
+
+// video returns the metadata to write, named name unless --title is set.
+func (f *videoFlags) video(name string) brightcove.Video {
+	video := brightcove.Video{
+		Name:         name,
+		CustomFields: f.customFields,
+	}
+	if f.title != "" {
+		video.Name = f.title
+	}
+	for _, tag := range strings.Split(f.tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			video.Tags = append(video.Tags, tag)
+		}
+	}
+	return video
+}
+
+// resourceFlags select a live resource by ID as an alternative to passing its
+// playback URL.
+type resourceFlags struct {
+	accountID  string
+	resourceID string
+	jobID      string
+}
+
+func (f *resourceFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.accountID, "account", envString("ACCOUNT_ID", ""), "account ID of the live resource, used with --resource (env ACCOUNT_ID)")
+	fs.StringVar(&f.resourceID, "resource", "", "ID of the live resource, instead of a playback URL")
+	fs.StringVar(&f.jobID, "job-id", "", "ID of the live job, whose resource is looked up with the Live Jobs API")
+}
+
+// set reports whether the resource was given by ID.
+func (f *resourceFlags) set() bool {
+	return f.resourceID != "" || f.jobID != ""
+}
+
+// resolve returns the account and resource IDs given by flag, looked up from
+// the live job or, when none were given, parsed from playbackURL.
+// defaultAccountID is used when --account is not set.
+func (f *resourceFlags) resolve(ctx context.Context, client *brightcove.Client, defaultAccountID, playbackURL string) (accountID, resourceID string, err error) {
+	if !f.set() {
+		return client.ResolvePlaybackURL(playbackURL)
+	}
+	if f.accountID == "" {
+		f.accountID = defaultAccountID
+	}
+	if f.resourceID != "" && f.jobID != "" {
+		return "", "", errors.New("expected either --resource or --job-id, not both")
+	}
+	if f.accountID == "" {
+		return "", "", errors.New("--resource and --job-id require --account")
+	}
+	if playbackURL != "" {
+		return "", "", errors.New("expected either a playback URL or --resource/--job-id, not both")
+	}
+	if f.resourceID != "" {
+		return f.accountID, f.resourceID, nil
+	}
+
+	job, err := client.GetJob(ctx, f.accountID, f.jobID)
+	if err != nil {
+		return "", "", withExitCode(exitAPI, fmt.Errorf("error getting live job: %w", err))
+	}
+	accountID, resourceID, err = job.Resource()
+	if err != nil {
+		return "", "", fmt.Errorf("error resolving live job %s: %w", f.jobID, err)
+	}
+	slog.InfoContext(ctx, "resolved live job", "job_id", job.ID, "account_id", accountID, "resource_id", resourceID)
+	return accountID, resourceID, nil
+}
+
+// expandHome replaces a leading ~ in path with the user's home directory,
+// for paths given as --flag=~/file where the shell doesn't expand it.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// envString returns the value of the environment variable name, or fallback
+// when it is unset.
+func envString(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// envInt returns the integer value of the environment variable name, or
+// fallback when it is unset or invalid.
+func envInt(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("ignoring invalid environment variable", "name", name, "value", value, "err", err)
+		return fallback
+	}
+	return n
+}
+
+// envFloat returns the float value of the environment variable name, or
+// fallback when it is unset or invalid.
+func envFloat(name string, fallback float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		slog.Warn("ignoring invalid environment variable", "name", name, "value", value, "err", err)
+		return fallback
+	}
+	return f
+}
+
+// envDuration returns the duration value of the environment variable name, or
+// fallback when it is unset or invalid.
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("ignoring invalid environment variable", "name", name, "value", value, "err", err)
+		return fallback
+	}
+	return d
+}
+
+// setUsage makes -h and missing arguments print the command's usage lines and
+// description followed by its flags.
+func setUsage(fs *flag.FlagSet, description string, usage ...string) {
+	fs.Usage = func() {
+		w := fs.Output()
+		for i, line := range usage {
+			if i == 0 {
+				fmt.Fprintf(w, "Usage: %s\n", line)
+			} else {
+				fmt.Fprintf(w, "       %s\n", line)
+			}
+		}
+		fmt.Fprintf(w, "\n%s\n\nFlags:\n", description)
+		fs.PrintDefaults()
+	}
+}
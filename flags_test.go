@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestIndexFlag(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "0", want: 0},
+		{value: "3", want: 3},
+		{value: "-1", want: -1},
+		{value: "-5", wantErr: true},
+		{value: "first", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			index := -1
+			err := (*indexFlag)(&index).Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if err == nil && index != tt.want {
+				t.Errorf("got index %d, want %d", index, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,731 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/history"
+	"github.com/rahulbalajee/bc-vod-urls/internal/shortlink"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+const manifestFormatBoth = "both"
+
+func runGenerate(args []string) {
+	fs := flag.NewFlagSet("vodurls", flag.ExitOnError)
+	setUsage(fs, "Generates VOD URLs for the ended sessions of a NextGenLive live resource.",
+		"./vodurls [generate] [flags] <PLAYBACK_URL>...",
+		"./vodurls [generate] [flags] --account ID --resource ID",
+		"./vodurls [generate] [flags] --account ID --job-id ID",
+		"./vodurls [generate] [flags] --input <FILE|->",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	var rf resourceFlags
+	rf.register(fs)
+	var af auditFlags
+	af.register(fs)
+	var nf notifyFlags
+	nf.register(fs)
+	format := fs.String("format", "", "manifest format of the generated VOD URLs (hls, dash or both), defaults to the format of the playback URL, .mpd for dash and hls otherwise")
+	output := fs.String("output", outputText, "output format (text, json, jsonl for a JSON object per URL and line, embed for an HTML player snippet per URL, mrss for an MRSS feed, or table for an aligned table with color-coded status)")
+	feedTitle := fs.String("feed-title", defaultFeedTitle, "title of the feed of --output mrss")
+	feedLink := fs.String("feed-link", defaultFeedLink, "link of the feed of --output mrss, e.g. the site the recordings are published on")
+	registerTZ(fs)
+	tmpl := fs.String("template", "", "Go text/template rendered for every generated URL, e.g. '{{.SessionID}} {{.URL}}', overrides --output")
+	input := fs.String("input", "", "file with one playback URL per line, or - for stdin")
+	var sessionIDs stringList
+	fs.Var(&sessionIDs, "session", "only generate a VOD URL for the session with this ID (repeatable)")
+	sessionIndex := -1
+	fs.Var((*indexFlag)(&sessionIndex), "session-index", "only generate a VOD URL for the session at this 0-based position in the resource's session list")
+	latest := fs.Int("latest", 0, "only generate VOD URLs for the N most recent ended sessions")
+	oldest := fs.Int("oldest", 0, "only generate VOD URLs for the N earliest ended sessions")
+	trimStart := fs.String("trim-start", "", "cut this much from the start of each session (e.g. 90s or 2m), or start at this absolute epoch time")
+	trimEnd := fs.String("trim-end", "", "cut this much from the end of each session (e.g. 5m), or end at this absolute epoch time")
+	dedupeOverlaps := fs.Bool("dedupe-overlaps", false, "of sessions whose time ranges overlap, e.g. recordings of redundant jobs, only generate VOD URLs for the longest one")
+	mergeGap := fs.Duration("merge-gap", 0, "generate a single VOD URL for consecutive sessions at most this far apart, e.g. 5m for a stream that briefly dropped")
+	chunk := fs.Duration("chunk", 0, "split sessions into consecutive chunks this long, e.g. 1h, each with VOD URLs of its own")
+	var minRemaining dayDuration
+	fs.Var(&minRemaining, "min-remaining", "skip sessions with less than this much of their VOD window left, e.g. 2d or 12h")
+	jobDetails := fs.Bool("job-details", false, "fetch the live job of every session with the Live Jobs API and show its name, state and labels")
+	skipLive := fs.Bool("skip-live", false, "if the resource is live, skip the live session and generate VOD URLs for the ended ones, e.g. for 24/7 channels")
+	channel := fs.Bool("channel", false, "treat the resource as an always-on channel and generate VOD URLs for the window between --start and --end, cut out of its sessions even while it is live")
+	windowStart := fs.String("start", "", "start of the --channel window: an epoch time, an RFC 3339 time or a duration ago like 2h")
+	windowEnd := fs.String("end", "", "end of the --channel window, like --start, now by default")
+	wait := fs.Bool("wait", false, "if the resource is live, poll until the stream ends and generate the VOD URLs then")
+	waitFinishing := fs.Bool("wait-finishing", false, "if the resource is live but its live job is finishing, poll until the session closes and generate the VOD URLs then")
+	pollInterval := fs.Duration("poll-interval", time.Minute, "how often --wait and --wait-finishing check whether the stream ended")
+	verify := fs.Bool("verify", false, "fetch each generated URL and check it serves a valid HLS or DASH manifest")
+	inspect := fs.Bool("inspect", false, "parse each generated HLS playlist and report its duration, segments and renditions")
+	maxResolution := fs.String("max-resolution", "", "emit the media playlist URL of the highest bandwidth HLS rendition at most this high, e.g. 720p or 1280x720, instead of the master playlist URL")
+	audioOnly := fs.Bool("audio-only", false, "emit the media playlist URL of the audio rendition of HLS VODs instead of the master playlist URL")
+	shorten := fs.String("shorten", envString("SHORTENER_URL", ""), "POST each generated URL to this link shortener and print the short link next to it (env SHORTENER_URL)")
+	shortenField := fs.String("shorten-field", "short_url", "field of the shortener's JSON response holding the short link")
+	thumbnailDir := fs.String("thumbnails", "", "grab a frame near the start of each session's VOD with ffmpeg and write it to this directory as a JPEG")
+	qrDir := fs.String("qr", "", "write a PNG QR code of each generated URL to this directory, or - to print them in the terminal")
+	noProgress := fs.Bool("no-progress", false, "do not report the progress of batch runs")
+	failFast := fs.Bool("fail-fast", false, "stop at the first failing session or playback URL instead of carrying on with the rest")
+	concurrency := fs.Int("concurrency", envInt("CONCURRENCY", 1), "number of playback token and URL requests issued in parallel (env CONCURRENCY)")
+	batchConcurrency := fs.Int("batch-concurrency", envInt("BATCH_CONCURRENCY", 1), "number of playback URLs of a batch processed in parallel, taking turns between accounts (env BATCH_CONCURRENCY)")
+	accountConcurrency := fs.Int("account-concurrency", envInt("ACCOUNT_CONCURRENCY", 0), "at most this many playback URLs of the same account processed in parallel in a batch, 0 for no limit (env ACCOUNT_CONCURRENCY)")
+	db := fs.String("db", envString("HISTORY_DB", ""), "record generated URLs in this SQLite history database, e.g. "+defaultHistoryDB+" (env HISTORY_DB)")
+	checkpointPath := fs.String("checkpoint", "", "record the playback URLs of a batch in this file as they complete, so the run can be resumed with --resume")
+	resume := fs.String("resume", "", "skip the playback URLs of a batch this checkpoint file records as completed, and keep recording to it")
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() == 0 && *input == "" && !rf.set() {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	out, err := newOutputOptions(*output, *tmpl, cf.quiet)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	out.feed = feedInfo{title: *feedTitle, link: *feedLink}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	notifiers, err := nf.notifiers(&cf)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	formats, err := parseFormat(*format)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	trim, err := parseTrimRange(*trimStart, *trimEnd)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	selection := sessionSelection{ids: sessionIDs, index: sessionIndex, latest: *latest, oldest: *oldest}
+	if err := selection.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	var window *channelWindow
+	if *channel {
+		if !selection.empty() {
+			slog.Error("--channel generates VOD URLs for a time window, it can't be combined with --session, --session-index, --latest or --oldest")
+			exit(1)
+		}
+		if window, err = parseChannelWindow(*windowStart, *windowEnd, time.Now()); err != nil {
+			slog.Error(err.Error())
+			exit(1)
+		}
+	} else if *windowStart != "" || *windowEnd != "" {
+		slog.Error("--start and --end require --channel, use --trim-start and --trim-end to trim sessions")
+		exit(1)
+	}
+
+	if *concurrency < 1 {
+		slog.Error("concurrency must be at least 1", "concurrency", *concurrency)
+		exit(1)
+	}
+	if *batchConcurrency < 1 || *accountConcurrency < 0 {
+		slog.Error("batch concurrency must be at least 1 and account concurrency must not be negative", "batch_concurrency", *batchConcurrency, "account_concurrency", *accountConcurrency)
+		exit(1)
+	}
+
+	if *shorten != "" {
+		if u, err := url.Parse(*shorten); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			slog.Error("invalid shortener URL", "shorten", *shorten)
+			exit(1)
+		}
+	}
+
+	if *mergeGap < 0 {
+		slog.Error("merge gap must not be negative", "merge_gap", *mergeGap)
+		exit(1)
+	}
+	if *chunk != 0 && *chunk < time.Second {
+		slog.Error("chunk length must be at least a second", "chunk", *chunk)
+		exit(1)
+	}
+
+	if *pollInterval <= 0 {
+		slog.Error("poll interval must be positive", "poll_interval", *pollInterval)
+		exit(1)
+	}
+
+	if *thumbnailDir != "" {
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			slog.Error("ffmpeg is required for --thumbnails, install it", "err", err)
+			exit(1)
+		}
+	}
+
+	renditions, err := parseRenditionSelection(*maxResolution, *audioOnly)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	opts := generateOptions{
+		formats:            formats,
+		selection:          selection,
+		channel:            window,
+		trim:               trim,
+		wait:               *wait,
+		waitFinishing:      *waitFinishing,
+		jobID:              rf.jobID,
+		skipLive:           *skipLive,
+		dedupeOverlaps:     *dedupeOverlaps,
+		mergeGap:           *mergeGap,
+		chunk:              *chunk,
+		minRemaining:       time.Duration(minRemaining),
+		failFast:           *failFast,
+		summary:            &runSummary{},
+		batchConcurrency:   *batchConcurrency,
+		accountConcurrency: *accountConcurrency,
+		pollInterval:       *pollInterval,
+		qrDir:              expandHome(*qrDir),
+		listExpired:        out.listsExpired(),
+	}
+	if *verify {
+		opts.verifyClient = cf.mediaClient()
+	}
+	if *inspect {
+		opts.inspectClient = cf.mediaClient()
+	}
+	if *thumbnailDir != "" {
+		opts.thumbnailDir = expandHome(*thumbnailDir)
+		opts.thumbnailClient = cf.mediaClient()
+	}
+	if renditions != nil {
+		renditions.httpClient = cf.mediaClient()
+		opts.renditions = renditions
+	}
+	if *jobDetails {
+		opts.jobs = newJobLookup(rf.jobID)
+	}
+	if *shorten != "" {
+		opts.shortener = &shortlink.HTTP{
+			Endpoint: *shorten,
+			Field:    *shortenField,
+			Token:    os.Getenv("SHORTENER_TOKEN"),
+			Client:   cf.httpClient(),
+		}
+	}
+
+	// More than one playback URL, or --input, runs in batch mode
+	batchMode := *input != "" || fs.NArg() > 1
+	var playbackURLs []string
+	if batchMode {
+		if rf.set() {
+			slog.Error("--resource and --job-id take a single resource, not several playback URLs or --input")
+			exit(1)
+		}
+		playbackURLs = fs.Args()
+		if *input != "" {
+			inputs, err := readInputs(*input)
+			if err != nil {
+				slog.Error("error reading input", "err", err)
+				exit(1)
+			}
+			playbackURLs = append(playbackURLs, inputs...)
+		}
+		playbackURLs = dedupeResources(playbackURLs)
+	} else if *checkpointPath != "" || *resume != "" {
+		slog.Error("--checkpoint and --resume take a batch of several playback URLs or --input")
+		exit(1)
+	}
+	if *checkpointPath != "" && *resume != "" {
+		slog.Error("expected either --checkpoint or --resume, not both")
+		exit(1)
+	}
+	if *resume != "" {
+		*checkpointPath = *resume
+	}
+	if *checkpointPath != "" {
+		opts.checkpoint, err = openCheckpoint(expandHome(*checkpointPath), *resume != "")
+		if err != nil {
+			slog.Error(err.Error())
+			exit(1)
+		}
+		defer opts.checkpoint.Close()
+	}
+
+	opts.history, err = openHistory(*db)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	if opts.history != nil {
+		defer opts.history.Close()
+	}
+
+	opts.audit, err = af.open()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	if opts.audit != nil {
+		defer opts.audit.Close()
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	client.Concurrency = *concurrency
+	client.ContinueOnError = !*failFast
+	client.SkipLive = *skipLive
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	var accountID, resourceID string
+	if !batchMode {
+		accountID, resourceID, err = rf.resolve(ctx, client, cf.profile.AccountID, fs.Arg(0))
+		if err != nil {
+			slog.Error("error getting sessions", "err", err)
+			if err := out.writeError(os.Stdout, fs.Arg(0), "", "", err); err != nil {
+				slog.Error("error writing output", "err", err)
+			}
+			exit(exitCode(err))
+		}
+		opts.formats = inferFormats(opts.formats, fs.Arg(0))
+	}
+
+	// Authenticate up front so bad credentials fail before any other work
+	if _, err := client.AccessToken(ctx); err != nil {
+		slog.Error("error generating access token", "err", err)
+		var playbackURL string
+		if !batchMode {
+			playbackURL = fs.Arg(0)
+		}
+		if err := out.writeError(os.Stdout, playbackURL, accountID, resourceID, err); err != nil {
+			slog.Error("error writing output", "err", err)
+		}
+		exit(exitAuth)
+	}
+
+	if batchMode {
+		if !*noProgress && !cf.quiet {
+			opts.progress = newProgress(len(playbackURLs))
+			logger := slog.New(opts.progress.handler(slog.Default().Handler()))
+			slog.SetDefault(logger)
+			client.Logger = logger
+		}
+
+		if out.streams() {
+			opts.stream = newJSONLWriter(os.Stdout)
+		}
+		batch := runBatch(ctx, client, playbackURLs, opts)
+		opts.progress.finish()
+
+		// Streamed results were printed as each playback URL completed
+		if opts.stream == nil {
+			if err := out.writeBatch(os.Stdout, batch); err != nil {
+				slog.Error("error writing output", "err", err)
+				exit(1)
+			}
+		}
+		opts.summary.log(ctx)
+		notifyAll(ctx, notifiers, batch)
+
+		if failed := batch.failed(); failed == len(batch) {
+			slog.Error("every playback URL failed", "total", len(batch))
+			exit(batch.exitCode())
+		} else if failed > 0 {
+			slog.Error("some playback URLs failed", "failed", failed, "total", len(batch))
+			exit(exitPartial)
+		}
+		if n := batch.unverified(); n > 0 {
+			slog.Error("some VOD URLs failed verification", "unverified", n)
+			exit(exitPartial)
+		}
+		return
+	}
+
+	results, err := generate(ctx, client, accountID, resourceID, opts)
+	if err != nil {
+		slog.Error(err.Error())
+		if len(results) > 0 {
+			// Only the expired sessions of table output
+			if err := out.writeResults(os.Stdout, results); err != nil {
+				slog.Error("error writing output", "err", err)
+			}
+		} else if err := out.writeError(os.Stdout, fs.Arg(0), accountID, resourceID, err); err != nil {
+			slog.Error("error writing output", "err", err)
+		}
+		exit(exitCode(err))
+	}
+
+	if err := out.writeResults(os.Stdout, results); err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+	opts.summary.log(ctx)
+	notifyAll(ctx, notifiers, singleResult(accountID, resourceID, results))
+
+	if n := opts.summary.failed(); n > 0 {
+		slog.Error("some sessions failed", "failed", n)
+		exit(exitPartial)
+	}
+
+	if n := unverified(results); n > 0 {
+		slog.Error("some VOD URLs failed verification", "unverified", n)
+		exit(exitPartial)
+	}
+}
+
+// parseFormat turns a --format value into the manifest formats to request.
+// An empty format returns none, to be inferred with inferFormats.
+func parseFormat(format string) ([]string, error) {
+	switch format {
+	case "":
+		return nil, nil
+	case brightcove.ManifestFormatHLS, brightcove.ManifestFormatDASH:
+		return []string{format}, nil
+	case manifestFormatBoth:
+		return []string{brightcove.ManifestFormatHLS, brightcove.ManifestFormatDASH}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q, expected hls, dash or both", format)
+}
+
+// inferFormats returns formats, or when none were given the manifest format
+// of playbackURL, HLS unless it points at a DASH manifest.
+func inferFormats(formats []string, playbackURL string) []string {
+	if len(formats) > 0 {
+		return formats
+	}
+	if brightcove.PlaybackURLFormat(playbackURL) == brightcove.ManifestFormatDASH {
+		return []string{brightcove.ManifestFormatDASH}
+	}
+	return []string{brightcove.ManifestFormatHLS}
+}
+
+// generateOptions control which VOD URLs generate creates.
+type generateOptions struct {
+	formats   []string
+	selection sessionSelection
+	trim      trimRange
+	// channel, when set, replaces the selection of sessions with a time
+	// window cut out of them, a live session not blocking it.
+	channel *channelWindow
+	// wait polls the resource every pollInterval while it is live instead of
+	// failing right away.
+	wait         bool
+	pollInterval time.Duration
+	// waitFinishing polls the resource like wait, but only while the live
+	// job is finishing.
+	waitFinishing bool
+	// jobID is the job given with --job-id, whose state is checked when the
+	// live session doesn't report its own.
+	jobID string
+	// skipLive goes on with the ended sessions of a live resource, the
+	// client must have SkipLive set as well.
+	skipLive bool
+	// dedupeOverlaps keeps only the longest of overlapping sessions.
+	dedupeOverlaps bool
+	// mergeGap, when positive, merges sessions at most this far apart.
+	mergeGap time.Duration
+	// chunk, when positive, splits sessions into chunks this long.
+	chunk time.Duration
+	// minRemaining, when positive, skips sessions leaving the VOD window
+	// within this long.
+	minRemaining time.Duration
+	// failFast stops a batch at the first failing playback URL.
+	failFast bool
+	// batchConcurrency is how many playback URLs of a batch are processed in
+	// parallel, at most accountConcurrency of the same account unless it is 0.
+	batchConcurrency   int
+	accountConcurrency int
+	// history, when set, records every generated URL.
+	history *history.Store
+	// audit, when set, records every generated URL for compliance.
+	audit *auditLog
+	// renditions, when set, replaces HLS master playlist URLs with the URL
+	// of a single rendition.
+	renditions *renditionSelection
+	// verifyClient, when set, is used to fetch and check every manifest.
+	verifyClient *http.Client
+	// inspectClient, when set, is used to parse every HLS playlist.
+	inspectClient *http.Client
+	// thumbnailDir, when set, is where thumbnailClient writes a thumbnail of
+	// every session.
+	thumbnailDir    string
+	thumbnailClient *http.Client
+	// shortener, when set, creates a short link of every URL.
+	shortener shortlink.Shortener
+	// qrDir, when set, is where a QR code of every URL is written, or
+	// qrTerminal to print them.
+	qrDir string
+	// summary, when set, tallies generated, skipped and failed URLs.
+	summary *runSummary
+	// progress, when set, reports the progress of batch runs.
+	progress *progress
+	// checkpoint, when set, records the completed playback URLs of a batch.
+	checkpoint *checkpoint
+	// jobs, when set, adds the live job of every session to the results.
+	jobs *jobLookup
+	// stream, when set, prints the results of every playback URL of a batch
+	// as soon as it completes.
+	stream *jsonlWriter
+	// listExpired adds a record of every session skipped as outside the VOD
+	// window to the results, for table output.
+	listExpired bool
+}
+
+// generateURL runs the whole session lookup and VOD generation flow for a
+// single playback URL.
+func generateURL(ctx context.Context, client *brightcove.Client, playbackURL string, opts generateOptions) ([]sessionResult, error) {
+	accountID, resourceID, err := client.ResolvePlaybackURL(playbackURL)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sessions: %w", err)
+	}
+	opts.formats = inferFormats(opts.formats, playbackURL)
+
+	return generate(ctx, client, accountID, resourceID, opts)
+}
+
+// generate runs the whole session lookup and VOD generation flow for a single
+// resource.
+func generate(ctx context.Context, client *brightcove.Client, accountID, resourceID string, opts generateOptions) (_ []sessionResult, err error) {
+	ctx, span := tracer.Start(ctx, "generate", trace.WithAttributes(
+		attribute.String("account_id", accountID),
+		attribute.String("resource_id", resourceID),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if brightcove.IsRedundantGroupID(resourceID) {
+		return generateGroup(ctx, client, accountID, resourceID, opts)
+	}
+
+	sessions, err := getEndedSessions(ctx, client, accountID, resourceID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err = opts.selectSessions(sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	return generateSessions(ctx, client, accountID, resourceID, sessions, opts)
+}
+
+// generateSessions generates the VOD URLs of the selected sessions of a
+// resource. With opts.listExpired the records of the expired sessions are
+// returned along with ErrVODWindowExpired when none was left.
+func generateSessions(ctx context.Context, client *brightcove.Client, accountID, resourceID string, sessions *brightcove.Sessions, opts generateOptions) ([]sessionResult, error) {
+	sessions, dropped := checkOverlaps(ctx, sessions, opts.dedupeOverlaps)
+	opts.summary.addSkipped(dropped)
+
+	sessions = mergeSessions(ctx, sessions, opts.mergeGap)
+
+	sessions, err := opts.trim.apply(sessions)
+	if err != nil {
+		return nil, fmt.Errorf("error trimming sessions: %w", err)
+	}
+
+	sessions = chunkSessions(sessions, opts.chunk)
+
+	sessions, dropped, err = skipExpiring(ctx, sessions, client.VODWindowDays, opts.minRemaining)
+	opts.summary.addSkipped(dropped)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []sessionResult
+	for _, session := range sessions.Events {
+		if !session.WithinVODWindow(client.VODWindowDays) {
+			result := newSessionResult(session, client.VODWindowDays)
+			result.expired = true
+			expired = append(expired, result)
+		}
+	}
+	opts.summary.addSkipped(len(expired))
+
+	playbackTokens, err := client.GeneratePlaybackTokens(ctx, sessions, opts.formats...)
+	tokenFailures, err := opts.continueOnPartial(len(playbackTokens), err)
+	if err != nil {
+		err = withExitCode(exitAPI, fmt.Errorf("error creating playback token: %w", err))
+		// Table output still lists the sessions when all of them expired
+		if opts.listExpired && errors.Is(err, brightcove.ErrVODWindowExpired) {
+			return expired, err
+		}
+		return nil, err
+	}
+
+	playbackURLs, err := client.GeneratePlaybackURLs(ctx, playbackTokens, resourceID)
+	urlFailures, err := opts.continueOnPartial(len(playbackURLs), err)
+	if err != nil {
+		return nil, withExitCode(exitAPI, fmt.Errorf("error generating playback urls: %w", err))
+	}
+	opts.summary.addGenerated(len(playbackURLs))
+
+	results := groupBySession(playbackURLs, client.VODWindowDays)
+	results = addSessionErrors(results, append(tokenFailures, urlFailures...), client.VODWindowDays)
+	if opts.jobs != nil {
+		opts.jobs.addDetails(ctx, client, accountID, results)
+	}
+	if opts.renditions != nil {
+		opts.renditions.apply(ctx, results, opts.summary)
+	}
+	if opts.verifyClient != nil {
+		verifyResults(ctx, opts.verifyClient, results)
+	}
+	if opts.inspectClient != nil {
+		inspectResults(ctx, opts.inspectClient, results)
+	}
+	if opts.shortener != nil {
+		shortenResults(ctx, opts.shortener, results)
+	}
+	if opts.thumbnailDir != "" {
+		if err := writeThumbnails(ctx, opts.thumbnailClient, opts.thumbnailDir, results); err != nil {
+			return nil, err
+		}
+	}
+	if opts.qrDir != "" {
+		if err := writeQRCodes(opts.qrDir, results); err != nil {
+			return nil, err
+		}
+	}
+	if opts.audit != nil {
+		if err := opts.audit.record(results); err != nil {
+			return nil, err
+		}
+	}
+	if opts.history != nil {
+		recordHistory(ctx, opts.history, results)
+	}
+
+	if opts.listExpired && len(expired) > 0 {
+		results = append(results, expired...)
+		slices.SortStableFunc(results, func(a, b sessionResult) int { return a.StartTime - b.StartTime })
+	}
+	return results, nil
+}
+
+// liveJobState returns the state of the live job streaming the live session of
+// sessions, falling back to jobID when the session doesn't report its job. It
+// is empty when the job is unknown or can't be fetched.
+func liveJobState(ctx context.Context, client *brightcove.Client, accountID string, sessions *brightcove.Sessions, jobID string) string {
+	if live := sessions.Live(); live != nil && live.JobID != "" {
+		jobID = live.JobID
+	}
+	if jobID == "" {
+		return ""
+	}
+	job, err := client.GetJob(ctx, accountID, jobID)
+	if err != nil {
+		slog.WarnContext(ctx, "error getting the state of the live job", "job_id", jobID, "err", err)
+		return ""
+	}
+	return job.State
+}
+
+// selectSessions returns the sessions to generate VOD URLs for: the window of
+// opts.channel when set, the selected sessions otherwise.
+func (o generateOptions) selectSessions(sessions *brightcove.Sessions) (*brightcove.Sessions, error) {
+	if o.channel != nil {
+		sessions, err := o.channel.apply(sessions)
+		if err != nil {
+			return nil, fmt.Errorf("error cutting the channel window: %w", err)
+		}
+		return sessions, nil
+	}
+
+	sessions, err := o.selection.apply(sessions)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// continueOnPartial lets generation go on with the n results of a call that
+// failed for some sessions only, recording the failures in the summary and
+// returning them. Any other error is returned as is.
+func (o generateOptions) continueOnPartial(n int, err error) ([]*brightcove.SessionError, error) {
+	var partial *brightcove.PartialError
+	if n == 0 || !errors.As(err, &partial) {
+		return nil, err
+	}
+	o.summary.addFailure("", err)
+	return partial.Errors, nil
+}
+
+// getEndedSessions fetches the sessions of a resource, making sure none of
+// them is live unless opts.skipLive or opts.channel allow it. With opts.wait
+// set it keeps polling until the stream ends, with opts.waitFinishing only if
+// the live job is finishing.
+func getEndedSessions(ctx context.Context, client *brightcove.Client, accountID, resourceID string, opts generateOptions) (*brightcove.Sessions, error) {
+	finishing := false
+	for {
+		sessions, err := client.GetResourceSessions(ctx, accountID, resourceID)
+		if err != nil {
+			return nil, withExitCode(exitAPI, fmt.Errorf("error getting sessions: %w", err))
+		}
+
+		// A live session blocks VOD generation for the whole resource, even
+		// when it is not one of the selected sessions
+		err = sessions.CheckNotLive()
+		if err == nil || opts.skipLive || opts.channel != nil {
+			return sessions, nil
+		}
+		if !opts.wait && !finishing {
+			// A finishing job closes its session within minutes, which is
+			// worth waiting for rather than failing
+			if liveJobState(ctx, client, accountID, sessions, opts.jobID) != brightcove.JobStateFinishing {
+				return nil, fmt.Errorf("error creating playback token: %w", err)
+			}
+			if !opts.waitFinishing {
+				return nil, fmt.Errorf("error creating playback token: %w; its live job is finishing and the VOD is available in a few minutes, pass --wait-finishing to wait for it", err)
+			}
+			finishing = true
+		}
+
+		if finishing {
+			slog.InfoContext(ctx, "live job is finishing, waiting for the session to close", "resource_id", resourceID, "poll_interval", opts.pollInterval)
+		} else {
+			slog.InfoContext(ctx, "resource is live, waiting for the stream to end", "resource_id", resourceID, "poll_interval", opts.pollInterval)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("error waiting for the stream to end: %w", ctx.Err())
+		case <-time.After(opts.pollInterval):
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/vodurlspb"
+)
+
+// grpcServer exposes the server's VOD generation as the gRPC
+// vodurls.v1.VODURLService.
+type grpcServer struct {
+	vodurlspb.UnimplementedVODURLServiceServer
+
+	server *server
+}
+
+// newGRPCServer returns a gRPC server serving the VODURLService of s, along
+// with the standard health service.
+func newGRPCServer(s *server) *grpc.Server {
+	grpcSrv := grpc.NewServer()
+	vodurlspb.RegisterVODURLServiceServer(grpcSrv, &grpcServer{server: s})
+
+	s.grpcHealth = health.NewServer()
+	healthpb.RegisterHealthServer(grpcSrv, s.grpcHealth)
+	return grpcSrv
+}
+
+func (g *grpcServer) GenerateVODURLs(ctx context.Context, req *vodurlspb.GenerateVODURLsRequest) (*vodurlspb.GenerateVODURLsResponse, error) {
+	resp, err := g.server.generateVODURLs(ctx, requestFromProto(req))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return responseToProto(resp), nil
+}
+
+func (g *grpcServer) GenerateVODURLsBatch(req *vodurlspb.GenerateVODURLsBatchRequest, stream grpc.ServerStreamingServer[vodurlspb.GenerateVODURLsBatchResponse]) error {
+	if len(req.GetRequests()) == 0 {
+		return status.Error(codes.InvalidArgument, "requests are required")
+	}
+
+	ctx := stream.Context()
+	for i, r := range req.GetRequests() {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		msg := &vodurlspb.GenerateVODURLsBatchResponse{Index: int32(i)}
+		resp, err := g.server.generateVODURLs(ctx, requestFromProto(r))
+		if err != nil {
+			msg.Error = err.Error()
+		} else {
+			msg.Result = responseToProto(resp)
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// grpcError turns an error of generateVODURLs into a gRPC status.
+func grpcError(err error) error {
+	var badRequest *badRequestError
+	var code codes.Code
+	switch {
+	case errors.As(err, &badRequest):
+		code = codes.InvalidArgument
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, brightcove.ErrResourceNotFound), errors.Is(err, errNoSuchSession):
+		code = codes.NotFound
+	case errors.Is(err, brightcove.ErrLiveSessionActive), errors.Is(err, brightcove.ErrVODWindowExpired), errors.Is(err, brightcove.ErrNoSessions):
+		code = codes.FailedPrecondition
+	default:
+		// Like the 502 of the REST API, the failure is Brightcove's
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
+
+func requestFromProto(req *vodurlspb.GenerateVODURLsRequest) vodURLsRequest {
+	r := vodURLsRequest{
+		PlaybackURL: req.GetPlaybackUrl(),
+		AccountID:   req.GetAccountId(),
+		ResourceID:  req.GetResourceId(),
+		Format:      req.GetFormat(),
+		SessionIDs:  req.GetSessionIds(),
+	}
+	if req.SessionIndex != nil {
+		index := int(req.GetSessionIndex())
+		r.SessionIndex = &index
+	}
+	return r
+}
+
+func responseToProto(resp *vodURLsResponse) *vodurlspb.GenerateVODURLsResponse {
+	msg := &vodurlspb.GenerateVODURLsResponse{
+		AccountId:  resp.AccountID,
+		ResourceId: resp.ResourceID,
+	}
+	for _, result := range resp.Sessions {
+		session := &vodurlspb.Session{
+			SessionId: result.SessionID,
+			StartTime: int64(result.StartTime),
+			EndTime:   int64(result.EndTime),
+		}
+		for _, url := range result.URLs {
+			vodURL := &vodurlspb.VODURL{
+				Format: url.Format,
+				Url:    url.URL,
+				Token:  url.Token,
+			}
+			if !url.ExpiresAt.IsZero() {
+				vodURL.ExpiresAt = timestamppb.New(url.ExpiresAt)
+			}
+			session.Urls = append(session.Urls, vodURL)
+		}
+		msg.Sessions = append(msg.Sessions, session)
+	}
+	return msg
+}
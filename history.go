@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/history"
+)
+
+const defaultHistoryDB = "~/.bc-vod-urls.db"
+
+func runHistory(args []string) {
+	fs := flag.NewFlagSet("vodurls history", flag.ExitOnError)
+	setUsage(fs, "Queries the history of generated VOD URLs.",
+		"./vodurls history [--resource ID] [--session ID] [--since 72h]",
+	)
+	db := fs.String("db", envString("HISTORY_DB", defaultHistoryDB), "history database to query (env HISTORY_DB)")
+	account := fs.String("account", "", "only show URLs of this account ID")
+	resource := fs.String("resource", "", "only show URLs of this resource ID")
+	session := fs.String("session", "", "only show URLs of this session ID")
+	since := fs.Duration("since", 0, "only show URLs generated within this long, e.g. 72h")
+	limit := fs.Int("limit", 50, "maximum number of URLs to show, 0 means no limit")
+	output := fs.String("output", outputText, "output format (text or json)")
+	registerTZ(fs)
+	fs.Parse(args)
+
+	if *output != outputText && *output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", *output)
+		exit(1)
+	}
+
+	store, err := history.Open(expandHome(*db))
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	defer store.Close()
+
+	filter := history.Filter{
+		AccountID:  *account,
+		ResourceID: *resource,
+		SessionID:  *session,
+		Limit:      *limit,
+	}
+	if *since > 0 {
+		filter.Since = time.Now().Add(-*since)
+	}
+
+	entries, err := store.Query(context.Background(), filter)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if *output == outputJSON {
+		err = writeJSON(os.Stdout, entries)
+	} else {
+		err = writeHistoryTable(os.Stdout, entries)
+	}
+	if err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+}
+
+func writeHistoryTable(w io.Writer, entries []history.Entry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GENERATED\tRESOURCE ID\tSESSION ID\tSTART\tFORMAT\tURL")
+
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			formatTime(e.GeneratedAt),
+			e.ResourceID,
+			e.SessionID,
+			formatEpoch(e.StartTime),
+			e.Format,
+			e.URL,
+		)
+	}
+
+	return tw.Flush()
+}
+
+// recordHistory stores generated URLs in the history database. Failing to do
+// so is logged but doesn't fail the generation.
+func recordHistory(ctx context.Context, store *history.Store, results []sessionResult) {
+	var entries []history.Entry
+	for _, result := range results {
+		for _, url := range result.URLs {
+			entries = append(entries, history.Entry{
+				AccountID:  result.AccountID,
+				ResourceID: result.ResourceID,
+				SessionID:  result.SessionID,
+				StartTime:  result.StartTime,
+				EndTime:    result.EndTime,
+				Format:     url.Format,
+				URL:        url.URL,
+				ExpiresAt:  url.ExpiresAt,
+			})
+		}
+	}
+
+	if err := store.Record(ctx, entries); err != nil {
+		slog.WarnContext(ctx, "error recording history", "err", err)
+	}
+}
+
+// openHistory opens the history database at path, or returns nil if path is
+// empty.
+func openHistory(path string) (*history.Store, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return history.Open(expandHome(path))
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/hls"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// truncationTolerance is the share of the session length a VOD may be missing
+// before it is reported as truncated.
+const truncationTolerance = 0.05
+
+// manifestInfo is what --inspect reports about a generated HLS VOD.
+type manifestInfo struct {
+	DurationSeconds float64     `json:"duration_seconds"`
+	Segments        int         `json:"segments"`
+	Renditions      []rendition `json:"renditions"`
+	// Truncated is set when the VOD is noticeably shorter than its session.
+	Truncated bool   `json:"truncated"`
+	Error     string `json:"error,omitempty"`
+}
+
+type rendition struct {
+	Resolution string `json:"resolution,omitempty"`
+	Bandwidth  int    `json:"bandwidth"`
+	Codecs     string `json:"codecs,omitempty"`
+}
+
+func (r rendition) String() string {
+	s := fmt.Sprintf("%dkbps", r.Bandwidth/1000)
+	if r.Resolution != "" {
+		s = r.Resolution + "@" + s
+	}
+	return s
+}
+
+// inspectResults parses the playlists of every generated HLS URL.
+func inspectResults(ctx context.Context, httpClient *http.Client, results []sessionResult) {
+	for i := range results {
+		result := &results[i]
+		expected := time.Duration(result.EndTime-result.StartTime) * time.Second
+
+		for j := range result.URLs {
+			url := &result.URLs[j]
+			if url.Format != brightcove.ManifestFormatHLS {
+				continue
+			}
+
+			info, err := inspectHLS(ctx, httpClient, url.URL)
+			if err != nil {
+				slog.WarnContext(ctx, "error inspecting VOD", "session_id", result.SessionID, "err", err)
+				info = &manifestInfo{Error: err.Error()}
+			} else if duration := time.Duration(info.DurationSeconds * float64(time.Second)); duration < expected-time.Duration(float64(expected)*truncationTolerance) {
+				info.Truncated = true
+				slog.WarnContext(ctx, "VOD looks truncated", "session_id", result.SessionID, "duration", duration.Round(time.Second), "session_duration", expected)
+			}
+			url.Inspection = info
+		}
+	}
+}
+
+// inspectHLS fetches a master playlist and the media playlist of its highest
+// bandwidth variant.
+func inspectHLS(ctx context.Context, httpClient *http.Client, url string) (*manifestInfo, error) {
+	body, err := fetchManifest(ctx, httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &manifestInfo{}
+	mediaURL := url
+	if hls.IsMaster(body) {
+		master, err := hls.ParseMaster(body, url)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing master playlist: %w", err)
+		}
+		if len(master.Variants) == 0 {
+			return nil, fmt.Errorf("master playlist has no variants")
+		}
+
+		for _, v := range master.Variants {
+			info.Renditions = append(info.Renditions, rendition{
+				Resolution: v.Resolution,
+				Bandwidth:  v.Bandwidth,
+				Codecs:     v.Codecs,
+			})
+		}
+		slices.SortFunc(info.Renditions, func(a, b rendition) int { return b.Bandwidth - a.Bandwidth })
+
+		best := slices.MaxFunc(master.Variants, func(a, b hls.Variant) int { return a.Bandwidth - b.Bandwidth })
+		mediaURL = best.URI
+		if body, err = fetchManifest(ctx, httpClient, mediaURL); err != nil {
+			return nil, err
+		}
+	}
+
+	media, err := hls.ParseMedia(body, mediaURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing media playlist: %w", err)
+	}
+
+	info.DurationSeconds = media.Duration().Seconds()
+	info.Segments = len(media.Segments)
+
+	return info, nil
+}
+
+// inspectNote summarizes an inspection in text output.
+func inspectNote(info *manifestInfo) string {
+	if info == nil {
+		return ""
+	}
+	if info.Error != "" {
+		return "  inspection failed: " + info.Error
+	}
+
+	var renditions []string
+	for _, r := range info.Renditions {
+		renditions = append(renditions, r.String())
+	}
+
+	note := fmt.Sprintf("  duration %s, %d segments", (time.Duration(info.DurationSeconds) * time.Second).String(), info.Segments)
+	if len(renditions) > 0 {
+		note += ", renditions " + strings.Join(renditions, " ")
+	}
+	if info.Truncated {
+		note += " (TRUNCATED)"
+	}
+	return note
+}
@@ -0,0 +1,152 @@
+// Package audit keeps an append-only JSON lines record of every generated
+// playback token and VOD URL for compliance.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedLayout is the timestamp suffix of rotated audit files.
+const rotatedLayout = "20060102T150405.000000000"
+
+// Entry is a single generated VOD URL. The playback token is only recorded as
+// a SHA-256 digest so the log doesn't hand out access.
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Operator    string    `json:"operator"`
+	AccountID   string    `json:"account_id"`
+	ResourceID  string    `json:"resource_id"`
+	SessionID   string    `json:"session_id"`
+	Format      string    `json:"format"`
+	TokenSHA256 string    `json:"token_sha256"`
+	URL         string    `json:"url"`
+}
+
+// Log is an audit file, rotated once it grows past MaxSize.
+type Log struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// Open opens the audit file at path for appending, creating it if needed.
+// When maxSize is positive, the file is renamed to path.<timestamp> before it
+// would grow past maxSize bytes and only the maxBackups most recent rotated
+// files are kept, all of them if maxBackups is 0.
+func Open(path string, maxSize int64, maxBackups int) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("error creating audit log directory: %w", err)
+	}
+
+	l := &Log{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error opening audit log: %w", err)
+	}
+
+	l.f = f
+	l.size = info.Size()
+	return nil
+}
+
+// Record appends entries and syncs them to disk.
+func (l *Log) Record(entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return fmt.Errorf("error encoding audit entry: %w", err)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(buf.Len()) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.f.Write(buf.Bytes())
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+	if err := l.f.Sync(); err != nil {
+		return fmt.Errorf("error syncing audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate moves the current file aside, starts a new one and prunes the
+// oldest rotated files.
+func (l *Log) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("error closing audit log: %w", err)
+	}
+	rotated := l.path + "." + time.Now().UTC().Format(rotatedLayout)
+	if err := os.Rename(l.path, rotated); err != nil {
+		return fmt.Errorf("error rotating audit log: %w", err)
+	}
+	if err := l.open(); err != nil {
+		return err
+	}
+
+	if l.maxBackups <= 0 {
+		return nil
+	}
+	matches, err := filepath.Glob(l.path + ".*")
+	if err != nil {
+		return fmt.Errorf("error listing rotated audit logs: %w", err)
+	}
+	var backups []string
+	for _, match := range matches {
+		if _, err := time.Parse(rotatedLayout, strings.TrimPrefix(match, l.path+".")); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	// Timestamps sort chronologically
+	slices.Sort(backups)
+	for _, backup := range backups[:max(0, len(backups)-l.maxBackups)] {
+		if err := os.Remove(backup); err != nil {
+			return fmt.Errorf("error removing rotated audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the audit file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
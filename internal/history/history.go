@@ -0,0 +1,240 @@
+// Package history keeps a SQLite record of every generated VOD URL so URLs
+// that were only printed to a terminal aren't lost.
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS vod_urls (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	generated_at INTEGER NOT NULL,
+	account_id   TEXT NOT NULL,
+	resource_id  TEXT NOT NULL,
+	session_id   TEXT NOT NULL,
+	start_time   INTEGER NOT NULL,
+	end_time     INTEGER NOT NULL,
+	format       TEXT NOT NULL,
+	url          TEXT NOT NULL,
+	expires_at   INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS vod_urls_resource ON vod_urls (account_id, resource_id);
+CREATE INDEX IF NOT EXISTS vod_urls_session ON vod_urls (session_id);
+`
+
+// Entry is a single generated VOD URL.
+type Entry struct {
+	ID          int64     `json:"id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	AccountID   string    `json:"account_id"`
+	ResourceID  string    `json:"resource_id"`
+	SessionID   string    `json:"session_id"`
+	StartTime   int       `json:"start_time"`
+	EndTime     int       `json:"end_time"`
+	Format      string    `json:"format"`
+	URL         string    `json:"url"`
+	// ExpiresAt is when the playback token of the URL expires, zero for
+	// URLs recorded before expiries were.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Filter narrows down a Query. Zero fields match everything.
+type Filter struct {
+	AccountID  string
+	ResourceID string
+	SessionID  string
+	Since      time.Time
+	Limit      int
+}
+
+// Store is a history database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the history database at path, creating it if needed.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("error creating history directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening history: %w", err)
+	}
+	// SQLite allows a single writer, serialize access instead of failing with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating history schema: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrating history schema: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// migrate adds the columns introduced after a database was created.
+func migrate(db *sql.DB) error {
+	var hasExpiresAt bool
+	err := db.QueryRow(`SELECT COUNT(*) > 0 FROM pragma_table_info('vod_urls') WHERE name = 'expires_at'`).Scan(&hasExpiresAt)
+	if err != nil {
+		return err
+	}
+	if !hasExpiresAt {
+		_, err = db.Exec(`ALTER TABLE vod_urls ADD COLUMN expires_at INTEGER NOT NULL DEFAULT 0`)
+	}
+	return err
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record stores entries in a single transaction. GeneratedAt defaults to now.
+func (s *Store) Record(ctx context.Context, entries []Entry) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error recording history: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, e := range entries {
+		if e.GeneratedAt.IsZero() {
+			e.GeneratedAt = now
+		}
+		var expiresAt int64
+		if !e.ExpiresAt.IsZero() {
+			expiresAt = e.ExpiresAt.Unix()
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO vod_urls (generated_at, account_id, resource_id, session_id, start_time, end_time, format, url, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			e.GeneratedAt.Unix(), e.AccountID, e.ResourceID, e.SessionID, e.StartTime, e.EndTime, e.Format, e.URL, expiresAt,
+		)
+		if err != nil {
+			return fmt.Errorf("error recording history: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error recording history: %w", err)
+	}
+	return nil
+}
+
+// Query returns the entries matching filter, most recent first.
+func (s *Store) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	var where []string
+	var args []any
+	if filter.AccountID != "" {
+		where = append(where, "account_id = ?")
+		args = append(args, filter.AccountID)
+	}
+	if filter.ResourceID != "" {
+		where = append(where, "resource_id = ?")
+		args = append(args, filter.ResourceID)
+	}
+	if filter.SessionID != "" {
+		where = append(where, "session_id = ?")
+		args = append(args, filter.SessionID)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "generated_at >= ?")
+		args = append(args, filter.Since.Unix())
+	}
+
+	query := selectEntries
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY generated_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	return s.query(ctx, query, args...)
+}
+
+// Expiring returns the most recent URL of every session and format whose
+// token expires before the given time, soonest first. URLs without a known
+// expiry are left out.
+func (s *Store) Expiring(ctx context.Context, before time.Time) ([]Entry, error) {
+	query := selectEntries + ` v WHERE expires_at > 0 AND expires_at < ? AND id = (
+		SELECT MAX(id) FROM vod_urls w
+		WHERE w.account_id = v.account_id AND w.resource_id = v.resource_id AND w.session_id = v.session_id AND w.format = v.format
+	) ORDER BY expires_at, id`
+	return s.query(ctx, query, before.Unix())
+}
+
+// Resource identifies a live resource of the history.
+type Resource struct {
+	AccountID  string `json:"account_id"`
+	ResourceID string `json:"resource_id"`
+}
+
+// Resources returns every resource URLs were recorded for, the most recently
+// generated first.
+func (s *Store) Resources(ctx context.Context) ([]Resource, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT account_id, resource_id FROM vod_urls GROUP BY account_id, resource_id ORDER BY MAX(id) DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying history: %w", err)
+	}
+	defer rows.Close()
+
+	var resources []Resource
+	for rows.Next() {
+		var r Resource
+		if err := rows.Scan(&r.AccountID, &r.ResourceID); err != nil {
+			return nil, fmt.Errorf("error querying history: %w", err)
+		}
+		resources = append(resources, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying history: %w", err)
+	}
+	return resources, nil
+}
+
+const selectEntries = `SELECT id, generated_at, account_id, resource_id, session_id, start_time, end_time, format, url, expires_at FROM vod_urls`
+
+func (s *Store) query(ctx context.Context, query string, args ...any) ([]Entry, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var e Entry
+		var generatedAt, expiresAt int64
+		if err := rows.Scan(&e.ID, &generatedAt, &e.AccountID, &e.ResourceID, &e.SessionID, &e.StartTime, &e.EndTime, &e.Format, &e.URL, &expiresAt); err != nil {
+			return nil, fmt.Errorf("error querying history: %w", err)
+		}
+		e.GeneratedAt = time.Unix(generatedAt, 0)
+		if expiresAt > 0 {
+			e.ExpiresAt = time.Unix(expiresAt, 0).UTC()
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying history: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,239 @@
+// Package hls parses the subset of HLS master and media playlists needed to
+// inspect generated VODs.
+package hls
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Variant is an #EXT-X-STREAM-INF rendition of a master playlist.
+type Variant struct {
+	Bandwidth        int
+	AverageBandwidth int
+	Resolution       string
+	Codecs           string
+	// URI is the absolute URL of the variant's media playlist.
+	URI string
+}
+
+// Media is an #EXT-X-MEDIA alternative rendition (audio, subtitles, ...).
+type Media struct {
+	Type     string
+	GroupID  string
+	Name     string
+	Language string
+	Default  bool
+	// URI is the absolute URL of the rendition's media playlist, if any.
+	URI string
+}
+
+// MasterPlaylist lists the renditions of a stream.
+type MasterPlaylist struct {
+	Variants []Variant
+	Media    []Media
+}
+
+// Segment is a media segment of a media playlist.
+type Segment struct {
+	Duration time.Duration
+	// URI is the absolute URL of the segment.
+	URI string
+}
+
+// MediaPlaylist lists the segments of a single rendition.
+type MediaPlaylist struct {
+	TargetDuration time.Duration
+	Segments       []Segment
+	// Map is the absolute URL of the initialization segment (#EXT-X-MAP) of
+	// fragmented MP4 playlists.
+	Map string
+	// Encrypted is set when segments are encrypted (#EXT-X-KEY).
+	Encrypted bool
+	// EndList is set when the playlist is complete (#EXT-X-ENDLIST).
+	EndList bool
+}
+
+// Duration is the total duration of all segments.
+func (p *MediaPlaylist) Duration() time.Duration {
+	var d time.Duration
+	for _, s := range p.Segments {
+		d += s.Duration
+	}
+	return d
+}
+
+// IsMaster reports whether data is a master playlist rather than a media one.
+func IsMaster(data []byte) bool {
+	return bytes.Contains(data, []byte("#EXT-X-STREAM-INF"))
+}
+
+// ParseMaster parses a master playlist. Relative URIs are resolved against
+// base, the URL the playlist was fetched from.
+func ParseMaster(data []byte, base string) (*MasterPlaylist, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing playlist URL: %w", err)
+	}
+
+	lines, err := playlistLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var playlist MasterPlaylist
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+			attrs := parseAttributes(strings.TrimPrefix(line, "#EXT-X-STREAM-INF:"))
+			variant := Variant{
+				Resolution: attrs["RESOLUTION"],
+				Codecs:     attrs["CODECS"],
+			}
+			variant.Bandwidth, _ = strconv.Atoi(attrs["BANDWIDTH"])
+			variant.AverageBandwidth, _ = strconv.Atoi(attrs["AVERAGE-BANDWIDTH"])
+
+			// The variant URI is the next line that isn't a tag
+			for i+1 < len(lines) && strings.HasPrefix(lines[i+1], "#") {
+				i++
+			}
+			if i+1 < len(lines) {
+				i++
+				variant.URI = resolve(baseURL, lines[i])
+			}
+			playlist.Variants = append(playlist.Variants, variant)
+
+		case strings.HasPrefix(line, "#EXT-X-MEDIA:"):
+			attrs := parseAttributes(strings.TrimPrefix(line, "#EXT-X-MEDIA:"))
+			media := Media{
+				Type:     attrs["TYPE"],
+				GroupID:  attrs["GROUP-ID"],
+				Name:     attrs["NAME"],
+				Language: attrs["LANGUAGE"],
+				Default:  attrs["DEFAULT"] == "YES",
+			}
+			if uri := attrs["URI"]; uri != "" {
+				media.URI = resolve(baseURL, uri)
+			}
+			playlist.Media = append(playlist.Media, media)
+		}
+	}
+
+	return &playlist, nil
+}
+
+// ParseMedia parses a media playlist. Relative URIs are resolved against base,
+// the URL the playlist was fetched from.
+func ParseMedia(data []byte, base string) (*MediaPlaylist, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing playlist URL: %w", err)
+	}
+
+	lines, err := playlistLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var playlist MediaPlaylist
+	var duration time.Duration
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-TARGETDURATION:"):
+			seconds, _ := strconv.Atoi(strings.TrimPrefix(line, "#EXT-X-TARGETDURATION:"))
+			playlist.TargetDuration = time.Duration(seconds) * time.Second
+		case strings.HasPrefix(line, "#EXTINF:"):
+			value, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid segment duration %q", value)
+			}
+			duration = time.Duration(seconds * float64(time.Second))
+		case strings.HasPrefix(line, "#EXT-X-MAP:"):
+			attrs := parseAttributes(strings.TrimPrefix(line, "#EXT-X-MAP:"))
+			if uri := attrs["URI"]; uri != "" {
+				playlist.Map = resolve(baseURL, uri)
+			}
+		case strings.HasPrefix(line, "#EXT-X-KEY:"):
+			attrs := parseAttributes(strings.TrimPrefix(line, "#EXT-X-KEY:"))
+			if attrs["METHOD"] != "" && attrs["METHOD"] != "NONE" {
+				playlist.Encrypted = true
+			}
+		case line == "#EXT-X-ENDLIST":
+			playlist.EndList = true
+		case !strings.HasPrefix(line, "#"):
+			playlist.Segments = append(playlist.Segments, Segment{
+				Duration: duration,
+				URI:      resolve(baseURL, line),
+			})
+			duration = 0
+		}
+	}
+
+	return &playlist, nil
+}
+
+// playlistLines returns the non-empty lines of a playlist, checking it starts
+// with #EXTM3U.
+func playlistLines(data []byte) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading playlist: %w", err)
+	}
+
+	if len(lines) == 0 || strings.TrimPrefix(lines[0], "\ufeff") != "#EXTM3U" {
+		return nil, errors.New("not an HLS playlist")
+	}
+
+	return lines[1:], nil
+}
+
+// parseAttributes parses an attribute list like BANDWIDTH=1280000,CODECS="a,b".
+func parseAttributes(list string) map[string]string {
+	attrs := map[string]string{}
+	for list != "" {
+		key, rest, ok := strings.Cut(list, "=")
+		if !ok {
+			break
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+			rest = "," + rest
+		}
+
+		attrs[strings.TrimSpace(key)] = value
+		list = strings.TrimPrefix(rest, ",")
+	}
+	return attrs
+}
+
+func resolve(base *url.URL, ref string) string {
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
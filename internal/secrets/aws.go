@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// AWSSecretsManager reads credentials from an AWS Secrets Manager secret whose
+// SecretString is a JSON object. AWS credentials and the region come from the
+// default AWS configuration chain (environment, shared config, instance or
+// task role).
+type AWSSecretsManager struct {
+	SecretID string
+
+	client *secretsmanager.Client
+}
+
+// NewAWSSecretsManager returns a provider for the secret with the given name
+// or ARN.
+func NewAWSSecretsManager(secretID string) (*AWSSecretsManager, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("error loading AWS configuration: %w", err)
+	}
+
+	return &AWSSecretsManager{
+		SecretID: secretID,
+		client:   secretsmanager.NewFromConfig(cfg),
+	}, nil
+}
+
+// Credentials reads the current version of the secret.
+func (s *AWSSecretsManager) Credentials(ctx context.Context) (brightcove.Credentials, error) {
+	out, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(s.SecretID),
+	})
+	if err != nil {
+		return brightcove.Credentials{}, fmt.Errorf("error getting secret %s: %w", s.SecretID, err)
+	}
+	if out.SecretString == nil {
+		return brightcove.Credentials{}, errors.New("secret has no string value")
+	}
+	return decodeCredentials([]byte(*out.SecretString))
+}
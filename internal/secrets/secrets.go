@@ -0,0 +1,50 @@
+// Package secrets fetches Brightcove client credentials from secret stores.
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// Open returns the credentials provider for a secret source, either
+// vault:<path> for a HashiCorp Vault KV secret or aws-sm:<secret-id> for an
+// AWS Secrets Manager secret. The secret must hold client_id and
+// client_secret keys.
+func Open(source string, httpClient *http.Client) (brightcove.CredentialsProvider, error) {
+	scheme, ref, ok := strings.Cut(source, ":")
+	if !ok || ref == "" {
+		return nil, fmt.Errorf("malformed secret source %q, expected vault:<path> or aws-sm:<secret-id>", source)
+	}
+
+	var provider brightcove.CredentialsProvider
+	var err error
+	switch scheme {
+	case "vault":
+		provider, err = NewVault(ref, httpClient)
+	case "aws-sm":
+		provider, err = NewAWSSecretsManager(ref)
+	default:
+		return nil, fmt.Errorf("unsupported secret source %q, expected vault or aws-sm", scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// decodeCredentials parses a JSON secret holding client_id and client_secret.
+func decodeCredentials(data []byte) (brightcove.Credentials, error) {
+	var creds brightcove.Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return brightcove.Credentials{}, fmt.Errorf("error decoding secret: %w", err)
+	}
+	if creds.ClientID == "" || creds.ClientSecret == "" {
+		return brightcove.Credentials{}, errors.New("secret is missing client_id or client_secret")
+	}
+	return creds, nil
+}
@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// Vault reads credentials from a HashiCorp Vault KV secret, version 2 or 1.
+// The server and token are taken from VAULT_ADDR and VAULT_TOKEN, and the
+// namespace from VAULT_NAMESPACE when set.
+type Vault struct {
+	Addr      string
+	Token     string
+	Namespace string
+	// Path is the API path of the secret below /v1/, e.g.
+	// secret/data/bc-vod-urls for the bc-vod-urls secret of the KV v2 engine
+	// mounted at secret/.
+	Path string
+
+	httpClient *http.Client
+}
+
+// NewVault returns a Vault provider for the secret at path, configured from
+// the standard Vault environment variables.
+func NewVault(path string, httpClient *http.Client) (*Vault, error) {
+	v := &Vault{
+		Addr:       os.Getenv("VAULT_ADDR"),
+		Token:      os.Getenv("VAULT_TOKEN"),
+		Namespace:  os.Getenv("VAULT_NAMESPACE"),
+		Path:       strings.Trim(path, "/"),
+		httpClient: httpClient,
+	}
+	if v.Addr == "" || v.Token == "" {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN must be set to read credentials from Vault")
+	}
+	if v.httpClient == nil {
+		v.httpClient = http.DefaultClient
+	}
+	return v, nil
+}
+
+// Credentials reads the secret from Vault.
+func (v *Vault) Credentials(ctx context.Context) (brightcove.Credentials, error) {
+	url := strings.TrimRight(v.Addr, "/") + "/v1/" + v.Path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return brightcove.Credentials{}, fmt.Errorf("error framing request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return brightcove.Credentials{}, fmt.Errorf("error getting response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return brightcove.Credentials{}, fmt.Errorf("error reading body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return brightcove.Credentials{}, fmt.Errorf("vault returned status %d for %s", resp.StatusCode, v.Path)
+	}
+
+	var secret struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return brightcove.Credentials{}, fmt.Errorf("error decoding body: %w", err)
+	}
+
+	// KV v2 nests the secret's keys in data.data next to its metadata
+	var kv2 struct {
+		Data     json.RawMessage `json:"data"`
+		Metadata json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(secret.Data, &kv2); err == nil && kv2.Data != nil && kv2.Metadata != nil {
+		return decodeCredentials(kv2.Data)
+	}
+	return decodeCredentials(secret.Data)
+}
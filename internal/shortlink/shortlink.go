@@ -0,0 +1,95 @@
+// Package shortlink turns the long VOD URLs into short links.
+package shortlink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Shortener returns a short link redirecting to a long URL.
+type Shortener interface {
+	Shorten(ctx context.Context, longURL string) (string, error)
+}
+
+// HTTP is a Shortener for the many services taking a POST of
+// {"url": "<long URL>"} and answering with the short link, either as a plain
+// text body or in a field of a JSON object.
+type HTTP struct {
+	// Endpoint is the URL the long URLs are POSTed to.
+	Endpoint string
+	// Field is the field of the JSON response holding the short link,
+	// "short_url" when empty.
+	Field string
+	// Token, when set, is sent as a bearer token.
+	Token string
+	// Client is the HTTP client used, http.DefaultClient when nil.
+	Client *http.Client
+}
+
+// maxResponseSize caps how much of a shortener response is read.
+const maxResponseSize = 64 << 10
+
+func (s *HTTP) Shorten(ctx context.Context, longURL string) (string, error) {
+	body, err := json.Marshal(map[string]string{"url": longURL})
+	if err != nil {
+		return "", fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("error framing request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/plain")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error calling shortener: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("error reading shortener response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("shortener returned status %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		short := strings.TrimSpace(string(respBody))
+		if short == "" {
+			return "", errors.New("shortener returned an empty response")
+		}
+		return short, nil
+	}
+
+	field := s.Field
+	if field == "" {
+		field = "short_url"
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(respBody, &fields); err != nil {
+		return "", fmt.Errorf("error decoding shortener response: %w", err)
+	}
+	short, ok := fields[field].(string)
+	if !ok || short == "" {
+		return "", fmt.Errorf("shortener response has no %q field", field)
+	}
+	return short, nil
+}
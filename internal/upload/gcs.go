@@ -0,0 +1,40 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+)
+
+// GCS is a Google Cloud Storage bucket. Credentials come from Application
+// Default Credentials (GOOGLE_APPLICATION_CREDENTIALS, gcloud or the metadata
+// server).
+type GCS struct {
+	Bucket string
+
+	client *storage.Client
+}
+
+// NewGCS returns the GCS bucket called bucket.
+func NewGCS(ctx context.Context, bucket string) (*GCS, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating GCS client: %w", err)
+	}
+
+	return &GCS{Bucket: bucket, client: client}, nil
+}
+
+func (b *GCS) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	w := b.client.Bucket(b.Bucket).Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	if _, err := w.Write(body); err != nil {
+		w.Close()
+		return fmt.Errorf("error uploading gs://%s/%s: %w", b.Bucket, key, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error uploading gs://%s/%s: %w", b.Bucket, key, err)
+	}
+	return nil
+}
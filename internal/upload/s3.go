@@ -0,0 +1,43 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3 is an Amazon S3 bucket. AWS credentials and the region come from the
+// default AWS configuration chain (environment, shared config, instance or
+// task role).
+type S3 struct {
+	Bucket string
+
+	client *s3.Client
+}
+
+// NewS3 returns the S3 bucket called bucket.
+func NewS3(ctx context.Context, bucket string) (*S3, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error loading AWS configuration: %w", err)
+	}
+
+	return &S3{Bucket: bucket, client: s3.NewFromConfig(cfg)}, nil
+}
+
+func (b *S3) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(b.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("error uploading s3://%s/%s: %w", b.Bucket, key, err)
+	}
+	return nil
+}
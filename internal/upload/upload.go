@@ -0,0 +1,51 @@
+// Package upload writes result files to object storage buckets.
+package upload
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Bucket stores objects in a bucket.
+type Bucket interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+// Open returns the bucket of a location given as s3://<bucket>/<key> for
+// Amazon S3 or gs://<bucket>/<key> for Google Cloud Storage, along with the
+// key.
+func Open(ctx context.Context, location string) (Bucket, string, error) {
+	scheme, name, key, err := parseLocation(location)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var bucket Bucket
+	switch scheme {
+	case "s3":
+		bucket, err = NewS3(ctx, name)
+	case "gs":
+		bucket, err = NewGCS(ctx, name)
+	default:
+		return nil, "", fmt.Errorf("unsupported upload location %q, expected s3 or gs", scheme)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return bucket, key, nil
+}
+
+// parseLocation splits <scheme>://<bucket>/<key>. It isn't parsed as a URL,
+// which would cut the key at a # or ? and decode its %xx sequences: the key is
+// taken as is.
+func parseLocation(location string) (scheme, bucket, key string, err error) {
+	scheme, rest, ok := strings.Cut(location, "://")
+	if ok {
+		bucket, key, ok = strings.Cut(rest, "/")
+	}
+	if !ok || scheme == "" || bucket == "" || strings.Trim(key, "/") == "" {
+		return "", "", "", fmt.Errorf("malformed upload location %q, expected s3://<bucket>/<key> or gs://<bucket>/<key>", location)
+	}
+	return scheme, bucket, key, nil
+}
@@ -0,0 +1,35 @@
+package upload
+
+import "testing"
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		scheme   string
+		bucket   string
+		key      string
+		wantErr  bool
+	}{
+		{location: "s3://bucket/urls.json", scheme: "s3", bucket: "bucket", key: "urls.json"},
+		{location: "gs://bucket/a/b/urls.csv", scheme: "gs", bucket: "bucket", key: "a/b/urls.csv"},
+		{location: "s3://bucket/event #1?.json", scheme: "s3", bucket: "bucket", key: "event #1?.json"},
+		{location: "s3://bucket/50%25 off%20now.csv", scheme: "s3", bucket: "bucket", key: "50%25 off%20now.csv"},
+		{location: "s3://bucket//urls.json", scheme: "s3", bucket: "bucket", key: "/urls.json"},
+		{location: "s3://bucket", wantErr: true},
+		{location: "s3://bucket/", wantErr: true},
+		{location: "s3:///urls.json", wantErr: true},
+		{location: "bucket/urls.json", wantErr: true},
+		{location: "://bucket/urls.json", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			scheme, bucket, key, err := parseLocation(tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if scheme != tt.scheme || bucket != tt.bucket || key != tt.key {
+				t.Errorf("got %q %q %q, want %q %q %q", scheme, bucket, key, tt.scheme, tt.bucket, tt.key)
+			}
+		})
+	}
+}
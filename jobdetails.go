@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// jobDetails identifies the live job that streamed a session in the output.
+type jobDetails struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	State  string   `json:"state"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// jobLookup fetches the live jobs of sessions with the Live Jobs API for
+// --job-details, once per job. It is safe for concurrent use.
+type jobLookup struct {
+	// jobID, when set, is the job of sessions that don't report theirs, e.g.
+	// the one given with --job-id.
+	jobID string
+
+	mu   sync.Mutex
+	jobs map[string]*jobDetails
+}
+
+func newJobLookup(jobID string) *jobLookup {
+	return &jobLookup{jobID: jobID, jobs: map[string]*jobDetails{}}
+}
+
+// addDetails sets the job of every result. A job that can't be fetched is
+// logged and left out, the URLs are usable without it.
+func (l *jobLookup) addDetails(ctx context.Context, client *brightcove.Client, accountID string, results []sessionResult) {
+	for i := range results {
+		jobID := results[i].jobID
+		if jobID == "" {
+			jobID = l.jobID
+		}
+		if jobID == "" {
+			continue
+		}
+		results[i].Job = l.get(ctx, client, accountID, jobID)
+	}
+}
+
+func (l *jobLookup) get(ctx context.Context, client *brightcove.Client, accountID, jobID string) *jobDetails {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := accountID + "/" + jobID
+	if details, ok := l.jobs[key]; ok {
+		return details
+	}
+
+	job, err := client.GetJob(ctx, accountID, jobID)
+	if err != nil {
+		slog.WarnContext(ctx, "error getting live job details", "job_id", jobID, "err", err)
+		l.jobs[key] = nil
+		return nil
+	}
+	details := &jobDetails{ID: job.ID, Name: job.Name, State: job.State, Labels: job.Labels}
+	l.jobs[key] = details
+	return details
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// jobInfo is the output record of the jobs list command.
+type jobInfo struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	State       string   `json:"state"`
+	Labels      []string `json:"labels,omitempty"`
+	AccountID   string   `json:"account_id"`
+	ResourceID  string   `json:"resource_id,omitempty"`
+	PlaybackURL string   `json:"playback_url,omitempty"`
+}
+
+func runJobs(args []string) {
+	if len(args) == 0 || args[0] != "list" {
+		fmt.Fprintln(os.Stderr, "Usage: ./vodurls jobs list [--account ID] [--state STATE] [--label LABEL] [--output text|json]")
+		exit(exitUsage)
+	}
+
+	fs := flag.NewFlagSet("vodurls jobs list", flag.ExitOnError)
+	setUsage(fs, "Lists the live jobs of an account with their resources and playback URLs, to find the resource to generate VOD URLs for.",
+		"./vodurls jobs list [--account ID] [--state STATE] [--label LABEL] [--output text|json]",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	accountID := fs.String("account", envString("ACCOUNT_ID", ""), "account ID whose jobs are listed, defaults to the profile's account_id (env ACCOUNT_ID)")
+	var states, labels stringList
+	fs.Var(&states, "state", "only list jobs in this state, e.g. processing or finished (repeatable)")
+	fs.Var(&labels, "label", "only list jobs carrying this label (repeatable, all must match)")
+	output := fs.String("output", outputText, "output format (text or json)")
+	if err := cf.parse(fs, args[1:]); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() > 0 {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if *output != outputText && *output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", *output)
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if *accountID == "" {
+		*accountID = cf.profile.AccountID
+	}
+	if *accountID == "" {
+		slog.Error("jobs list requires --account")
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	jobs, err := client.ListJobs(ctx, *accountID, brightcove.JobFilter{States: states, Labels: labels})
+	if err != nil {
+		slog.Error("error listing live jobs", "err", err)
+		exit(exitCode(err))
+	}
+
+	infos := make([]jobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		info := jobInfo{
+			ID:          job.ID,
+			Name:        job.Name,
+			State:       job.State,
+			Labels:      job.Labels,
+			AccountID:   job.AccountID,
+			PlaybackURL: job.PlaybackURL,
+		}
+		// The resource is what the other commands take
+		if _, resourceID, err := job.Resource(); err == nil {
+			info.ResourceID = resourceID
+		}
+		infos = append(infos, info)
+	}
+
+	if *output == outputJSON {
+		err = writeJSON(os.Stdout, infos)
+	} else {
+		err = writeJobsTable(os.Stdout, infos)
+	}
+	if err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+}
+
+func writeJobsTable(w io.Writer, jobs []jobInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB ID\tNAME\tSTATE\tRESOURCE ID\tLABELS\tPLAYBACK URL")
+
+	for _, job := range jobs {
+		resourceID, labels, playbackURL := job.ResourceID, strings.Join(job.Labels, ","), job.PlaybackURL
+		for _, field := range []*string{&resourceID, &labels, &playbackURL} {
+			if *field == "" {
+				*field = "-"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", job.ID, job.Name, job.State, resourceID, labels, playbackURL)
+	}
+
+	return tw.Flush()
+}
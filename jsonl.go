@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// urlRecord is the JSON Lines record of a single generated URL, holding its
+// session so it can be processed on its own.
+type urlRecord struct {
+	// PlaybackURL is the batch input the URL was generated from.
+	PlaybackURL         string      `json:"playback_url,omitempty"`
+	AccountID           string      `json:"account_id"`
+	ResourceID          string      `json:"resource_id"`
+	SessionID           string      `json:"session_id"`
+	StartTime           int         `json:"start_time"`
+	EndTime             int         `json:"end_time"`
+	VODExpiresAt        time.Time   `json:"vod_expires_at"`
+	VODRemainingSeconds int64       `json:"vod_remaining_seconds"`
+	Job                 *jobDetails `json:"job,omitempty"`
+	resultURL
+}
+
+// jsonlWriter writes one JSON object per line as results come in, safe for
+// concurrent use by the workers of a batch.
+type jsonlWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	return &jsonlWriter{enc: json.NewEncoder(w)}
+}
+
+// writeResults writes a record per URL of results, generated from
+// playbackURL, and one per URL that failed.
+func (j *jsonlWriter) writeResults(playbackURL string, results []sessionResult) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, result := range results {
+		for _, url := range result.URLs {
+			err := j.enc.Encode(urlRecord{
+				PlaybackURL:         playbackURL,
+				AccountID:           result.AccountID,
+				ResourceID:          result.ResourceID,
+				SessionID:           result.SessionID,
+				StartTime:           result.StartTime,
+				EndTime:             result.EndTime,
+				VODExpiresAt:        result.VODExpiresAt,
+				VODRemainingSeconds: result.VODRemainingSeconds,
+				Job:                 result.Job,
+				resultURL:           url,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, failure := range result.Errors {
+			err := j.enc.Encode(inputResult{
+				PlaybackURL: playbackURL,
+				AccountID:   result.AccountID,
+				ResourceID:  result.ResourceID,
+				Error:       failure.Message,
+				ErrorInfo:   &failure,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeInput writes the records of a processed batch input, or a record of
+// its error when it failed. A nil writer does nothing, so batches not
+// streaming their results don't need to check.
+func (j *jsonlWriter) writeInput(result inputResult) {
+	if j == nil {
+		return
+	}
+
+	var err error
+	if result.Error != "" {
+		j.mu.Lock()
+		err = j.enc.Encode(inputResult{PlaybackURL: result.PlaybackURL, AccountID: result.AccountID, ResourceID: result.ResourceID, Error: result.Error, ErrorInfo: result.ErrorInfo})
+		j.mu.Unlock()
+	} else {
+		err = j.writeResults(result.PlaybackURL, result.Sessions)
+	}
+	if err != nil {
+		slog.Warn("error writing output", "playback_url", result.PlaybackURL, "err", err)
+	}
+}
@@ -1,289 +1,100 @@
 package main
 
 import (
-	"bytes"
-	"encoding/base64"
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
 	"io"
-	"log"
-	"net/http"
-	"net/url"
+	"log/slog"
 	"os"
-	"strconv"
-	"strings"
-	"time"
-
-	"github.com/joho/godotenv"
-)
-
-const (
-	manifestFormatHLS  = "hls"
-	manifestFormatDASH = "dash"
-	vodWindowDuration  = 14
 )
 
-type application struct {
-	client *http.Client
-}
-
-type Token struct {
-	AccessToken string `json:"access_token"`
-	ExpiresIn   int    `json:"expires_in"`
-}
-
-type Sessions struct {
-	Events []Session `json:"sessions"`
-}
-
-type Session struct {
-	ID         string `json:"id"`
-	ResourceID string `json:"resource_id"`
-	AccountID  string `json:"account_id"`
-	StartTime  int    `json:"start_time"`
-	EndTime    int    `json:"end_time"`
+// command is a vodurls subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
 }
 
-type PlaybackToken struct {
-	Token string `json:"token"`
-}
-
-type PlaybackURL struct {
-	URL string `json:"url"`
-}
-
-func (app *application) generateToken(clientID, clientSecret string) (*Token, error) {
-	encodedCredentials := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", clientID, clientSecret)))
-
-	const url = "https://oauth.brightcove.com/v4/access_token"
-	payload := bytes.NewReader([]byte("grant_type=client_credentials"))
-	headers := http.Header{
-		"Content-Type":  {"application/x-www-form-urlencoded"},
-		"Authorization": {"Basic " + encodedCredentials},
-	}
-
-	body, err := app.doRequest(http.MethodPost, url, payload, headers)
-	if err != nil {
-		return nil, err
+// commands returns every subcommand in the order they are listed by help.
+func commands() []command {
+	return []command{
+		{"generate", "generate VOD URLs for the sessions of a live resource (the default)", runGenerate},
+		{"sessions", "list the sessions of a live resource", runSessions},
+		{"expiring", "report sessions whose VOD window ends soon", runExpiring},
+		{"stats", "report session counts, streamed hours and a per-day histogram of a live resource", runStats},
+		{"verify", "check VOD URLs serve valid manifests", runVerify},
+		{"preview", "serve a local page playing the VOD URLs of a live resource", runPreview},
+		{"token", "print an OAuth access token", runToken},
+		{"serve", "serve VOD URL generation over HTTP", runServe},
+		{"refresh", "regenerate VOD URLs of the history before they expire", runRefresh},
+		{"history", "query the history of generated VOD URLs", runHistory},
+		{"clip", "create permanent Video Cloud clips of sessions", runClip},
+		{"clips", "list the clips already created from a live resource", runClips},
+		{"jobs", "list the live jobs of an account", runJobs},
+		{"archive", "archive sessions into Video Cloud with Dynamic Ingest", runArchive},
+		{"download", "download an HLS VOD URL into a local file", runDownload},
+		{"credentials", "store client credentials in the OS keychain", runCredentials},
 	}
-
-	var token Token
-	if err = json.Unmarshal(body, &token); err != nil {
-		return nil, fmt.Errorf("error decoding body: %w", err)
-	}
-
-	return &token, nil
 }
 
-func (app *application) getSessions(token, playbackURL string) (*Sessions, string, error) {
-	// playbackURL should be of format https://fastly.live.brightcove.com/6384185469112/ap-south-1/6415518627001/eyJyui.../playlist-hls.m3u8
-	// parsedURL.Path would be would be /6384185469112/ap-south-1/6415518627001/eyJyui.../playlist-hls.m3u8
-	// pathParts[1] = VideoID/JobID/ResourceID pathParts[3] = AccountID
-	parsedURL, err := url.Parse(playbackURL)
-	if err != nil {
-		return nil, "", fmt.Errorf("error parsing playbackURL: %w", err)
-	}
-
-	pathParts := strings.Split(parsedURL.Path, "/")
-	if len(pathParts) < 6 {
-		return nil, "", errors.New("malformed playback URL provided")
-	}
-
-	var resourceID = pathParts[1]
-
-	url := fmt.Sprintf("https://api.live.brightcove.com/v2/accounts/%s/sessions/resource/%s", pathParts[3], pathParts[1])
-	headers := http.Header{
-		"Content-Type":  {"application/json"},
-		"Authorization": {"Bearer " + token},
-	}
-
-	body, err := app.doRequest(http.MethodGet, url, nil, headers)
+func main() {
+	// Load .env first so it can also provide defaults for flags
+	args, err := loadEnvFiles(os.Args[1:])
 	if err != nil {
-		return nil, "", err
+		slog.Error(err.Error())
+		exit(1)
 	}
 
-	var sessions Sessions
-	err = json.Unmarshal(body, &sessions)
-	if err != nil {
-		return nil, "", fmt.Errorf("error decoding body: %w", err)
+	if err := setupTracing(context.Background()); err != nil {
+		slog.Error(err.Error())
+		exit(1)
 	}
+	defer shutdownTracing()
 
-	return &sessions, resourceID, nil
-}
-
-func (app *application) generatePlaybackToken(sessions *Sessions, token string) ([]PlaybackToken, error) {
-	var url string
-	var playbackTokens []PlaybackToken
-
-	if len(sessions.Events) == 0 {
-		return nil, errors.New("no events in session, quitting")
-	}
-	// Check if any session is currently live (EndTime == 0)
-	// When a resource is live, the API won't allow VOD generation for ANY sessions
-	for _, session := range sessions.Events {
-		if session.EndTime == 0 {
-			return nil, fmt.Errorf("resource %s has an ongoing live session, cannot generate VOD URLs until the stream ends", session.ResourceID)
-		}
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		exit(exitUsage)
 	}
 
-	session := sessions.Events[0]
-
-	url = fmt.Sprintf("https://api.live.brightcove.com/v2/accounts/%s/playback/%s/token", session.AccountID, session.ResourceID)
-
-	for _, session := range sessions.Events {
-		// Checks if a session end time is within the last 14 days, otherwise skip generating token for that session
-		if time.Unix(int64(session.EndTime), 0).Before(time.Now().UTC().AddDate(0, 0, -vodWindowDuration)) {
-			fmt.Printf("resource %s was streamed before 14 days with end time %d, VOD window out of range\n", session.ID, session.EndTime)
-			continue
-		}
-		data := struct {
-			StartTime      string `json:"start_time"`
-			EndTime        string `json:"end_time"`
-			ManifestFormat string `json:"manifest_format"`
-		}{
-			StartTime:      strconv.Itoa(session.StartTime),
-			EndTime:        strconv.Itoa(session.EndTime),
-			ManifestFormat: manifestFormatHLS,
-		}
-		var buf bytes.Buffer
-		err := json.NewEncoder(&buf).Encode(data)
-		if err != nil {
-			return nil, fmt.Errorf("error encoding JSON: %w", err)
-		}
-
-		headers := http.Header{
-			"Content-Type":  {"application/json"},
-			"Authorization": {"Bearer " + token},
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			// Every command prints its help for -h
+			runCommand(args[1], []string{"-h"})
 		}
-
-		body, err := app.doRequest(http.MethodPost, url, &buf, headers)
-		if err != nil {
-			return nil, err
-		}
-
-		var playbackToken PlaybackToken
-		err = json.Unmarshal(body, &playbackToken)
-		if err != nil {
-			return nil, fmt.Errorf("error decoding body: %w", err)
-		}
-
-		playbackTokens = append(playbackTokens, playbackToken)
+		printUsage(os.Stdout)
+		return
 	}
 
-	if len(playbackTokens) == 0 {
-		return nil, errors.New("no valid sessions to continue")
+	if !runCommand(args[0], args[1:]) {
+		// Without a subcommand VOD URLs are generated, as before subcommands
+		// existed
+		runGenerate(args)
 	}
-
-	return playbackTokens, nil
 }
 
-func (app *application) generatePlaybackURL(tokens []PlaybackToken, resourceID string) ([]PlaybackURL, error) {
-	var playbackURLs []PlaybackURL
-
-	for _, token := range tokens {
-		url := fmt.Sprintf("https://api.live.brightcove.com/v2/playback/%s?pt=%s", resourceID, token.Token)
-		headers := http.Header{
-			"Content-Type": {"application/json"},
-		}
-
-		body, err := app.doRequest(http.MethodGet, url, nil, headers)
-		if err != nil {
-			return nil, err
-		}
-
-		var playbackURL PlaybackURL
-		err = json.Unmarshal(body, &playbackURL)
-		if err != nil {
-			return nil, fmt.Errorf("error decoding body: %w", err)
+// runCommand runs the subcommand called name, reporting whether it exists.
+func runCommand(name string, args []string) bool {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			cmd.run(args)
+			return true
 		}
-
-		playbackURLs = append(playbackURLs, playbackURL)
-	}
-
-	return playbackURLs, nil
-}
-
-func (app *application) doRequest(method, url string, payload io.Reader, headers http.Header) ([]byte, error) {
-	req, err := http.NewRequest(method, url, payload)
-	if err != nil {
-		return nil, fmt.Errorf("error framing request: %w", err)
 	}
-
-	for k, v := range headers {
-		req.Header.Set(k, v[0])
-	}
-
-	resp, err := app.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error getting response: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received error from API with status %d and error %s", resp.StatusCode, string(body))
-	}
-
-	return body, nil
+	return false
 }
 
-func main() {
-	args := os.Args
-	if len(args) == 1 {
-		fmt.Println("Usage: ./vodurls <PLAYBACK_URL>")
-		os.Exit(1)
-	}
-	playbackURL := args[1]
-
-	if err := godotenv.Load(); err != nil {
-		log.Println("error loading .env", err)
-		os.Exit(1)
-	}
-	clientID := os.Getenv("CLIENT_ID")
-	clientSecret := os.Getenv("CLIENT_SECRET")
-
-	if clientID == "" || clientSecret == "" {
-		log.Println("client credentials missing")
-		os.Exit(1)
-	}
-
-	app := application{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-	}
-
-	token, err := app.generateToken(clientID, clientSecret)
-	if err != nil {
-		log.Println("error generating access token:", err)
-		os.Exit(1)
-	}
-
-	sessions, resourceID, err := app.getSessions(token.AccessToken, playbackURL)
-	if err != nil {
-		log.Println("error getting sessions:", err)
-		os.Exit(1)
-	}
-
-	playbackTokens, err := app.generatePlaybackToken(sessions, token.AccessToken)
-	if err != nil {
-		log.Println("error creating playback token:", err)
-		os.Exit(1)
-	}
-
-	playbackURLs, err := app.generatePlaybackURL(playbackTokens, resourceID)
-	if err != nil {
-		log.Println("error generating playback urls:", err)
-		os.Exit(1)
-	}
-
-	for i, url := range playbackURLs {
-		fmt.Printf("\nVOD URL[%d]: %s\n", i, url.URL)
-	}
-	fmt.Println()
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: ./vodurls <command> [flags] [arguments]")
+	fmt.Fprintln(w, "       ./vodurls [flags] <PLAYBACK_URL>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	fmt.Fprintf(w, "  %-12s %s\n", "--env-file", "load environment variables from this file instead of ./.env (repeatable)")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run ./vodurls help <command> or ./vodurls <command> -h for the flags of a command.")
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// mergeSessions collapses ended sessions starting within gap of the end of
+// the previous one, e.g. the sessions of a stream that briefly dropped, into a
+// single session spanning them. A merged session's ID joins the IDs of its
+// sessions with "+". Sessions must be sorted by start time, live ones are
+// kept apart. A zero gap merges nothing.
+func mergeSessions(ctx context.Context, sessions *brightcove.Sessions, gap time.Duration) *brightcove.Sessions {
+	if gap <= 0 {
+		return sessions
+	}
+
+	merged := &brightcove.Sessions{}
+	var joined []int // indexes in merged of the sessions that were merged
+	for _, session := range sessions.Events {
+		if n := len(merged.Events); n > 0 {
+			last := &merged.Events[n-1]
+			if last.EndTime != 0 && session.EndTime != 0 && time.Duration(session.StartTime-last.EndTime)*time.Second <= gap {
+				if len(joined) == 0 || joined[len(joined)-1] != n-1 {
+					joined = append(joined, n-1)
+				}
+				last.ID += "+" + session.ID
+				last.EndTime = max(last.EndTime, session.EndTime)
+				continue
+			}
+		}
+		merged.Events = append(merged.Events, session)
+	}
+
+	for _, i := range joined {
+		session := merged.Events[i]
+		slog.InfoContext(ctx, "merged adjacent sessions", "session_ids", strings.Split(session.ID, "+"), "start_time", session.StartTime, "end_time", session.EndTime)
+	}
+	return merged
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+func TestMergeSessions(t *testing.T) {
+	const start = 1_700_000_000
+	sessions := &brightcove.Sessions{Events: []brightcove.Session{
+		{ID: "a", StartTime: start, EndTime: start + 600},
+		{ID: "b", StartTime: start + 630, EndTime: start + 1200},
+		{ID: "c", StartTime: start + 1500, EndTime: start + 1800},
+		{ID: "live", StartTime: start + 1830},
+	}}
+
+	tests := []struct {
+		name string
+		gap  time.Duration
+		want []brightcove.Session
+	}{
+		{name: "no gap", want: sessions.Events},
+		{name: "shorter gap", gap: 20 * time.Second, want: sessions.Events},
+		{
+			name: "gap reached",
+			gap:  30 * time.Second,
+			want: []brightcove.Session{
+				{ID: "a+b", StartTime: start, EndTime: start + 1200},
+				{ID: "c", StartTime: start + 1500, EndTime: start + 1800},
+				{ID: "live", StartTime: start + 1830},
+			},
+		},
+		{
+			name: "live kept apart",
+			gap:  10 * time.Minute,
+			want: []brightcove.Session{
+				{ID: "a+b+c", StartTime: start, EndTime: start + 1800},
+				{ID: "live", StartTime: start + 1830},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := mergeSessions(context.Background(), sessions, tt.gap)
+			if !slices.Equal(merged.Events, tt.want) {
+				t.Errorf("got %+v, want %+v", merged.Events, tt.want)
+			}
+		})
+	}
+}
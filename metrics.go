@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// metrics are the Prometheus metrics exported by serve on /metrics. They
+// implement brightcove.Observer to count the client's API calls.
+type metrics struct {
+	registry        *prometheus.Registry
+	apiRequests     *prometheus.CounterVec
+	apiDuration     *prometheus.HistogramVec
+	apiRetries      *prometheus.CounterVec
+	tokensGenerated *prometheus.CounterVec
+	vodURLs         prometheus.Counter
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		apiRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vodurls_api_requests_total",
+			Help: "Brightcove API calls by endpoint and response status, 0 when no response was received.",
+		}, []string{"endpoint", "status"}),
+		apiDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "vodurls_api_request_duration_seconds",
+			Help:    "Latency of Brightcove API calls by endpoint.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		apiRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vodurls_api_retries_total",
+			Help: "Retried Brightcove API calls by endpoint.",
+		}, []string{"endpoint"}),
+		tokensGenerated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vodurls_tokens_generated_total",
+			Help: "Tokens generated by kind, access or playback.",
+		}, []string{"kind"}),
+		vodURLs: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "vodurls_vod_urls_generated_total",
+			Help: "VOD URLs generated.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.apiRequests,
+		m.apiDuration,
+		m.apiRetries,
+		m.tokensGenerated,
+		m.vodURLs,
+	)
+	return m
+}
+
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *metrics) ObserveRequest(endpoint string, status int, duration time.Duration) {
+	m.apiRequests.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
+	m.apiDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+
+	if status != http.StatusOK {
+		return
+	}
+	switch endpoint {
+	case brightcove.EndpointAccessToken:
+		m.tokensGenerated.WithLabelValues("access").Inc()
+	case brightcove.EndpointPlaybackToken:
+		m.tokensGenerated.WithLabelValues("playback").Inc()
+	}
+}
+
+func (m *metrics) ObserveRetry(endpoint string) {
+	m.apiRetries.WithLabelValues(endpoint).Inc()
+}
+
+// addResults counts the VOD URLs of results.
+func (m *metrics) addResults(results []sessionResult) {
+	for _, result := range results {
+		m.vodURLs.Add(float64(len(result.URLs)))
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+const (
+	defaultFeedTitle = "Live VOD recordings"
+	defaultFeedLink  = "https://studio.brightcove.com"
+)
+
+// feedInfo describes the MRSS feed as a whole.
+type feedInfo struct {
+	title string
+	link  string
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Media   string     `xml:"xmlns:media,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	PubDate     string    `xml:"pubDate,omitempty"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title   string       `xml:"title"`
+	GUID    rssGUID      `xml:"guid"`
+	PubDate string       `xml:"pubDate"`
+	Content mediaContent `xml:"media:content"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type mediaContent struct {
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Medium   string `xml:"medium,attr"`
+	Duration int    `xml:"duration,attr,omitempty"`
+}
+
+// mediaTypes are the MIME types of the manifest formats.
+var mediaTypes = map[string]string{
+	brightcove.ManifestFormatHLS:  "application/x-mpegURL",
+	brightcove.ManifestFormatDASH: "application/dash+xml",
+}
+
+// writeMRSS renders every URL as an item of an MRSS feed, published when its
+// session ended. Sessions with a live job are titled after it.
+func writeMRSS(w io.Writer, feed feedInfo, results []sessionResult) error {
+	channel := rssChannel{
+		Title:       feed.title,
+		Link:        feed.link,
+		Description: "VOD recordings of live streams",
+		PubDate:     time.Now().UTC().Format(time.RFC1123Z),
+	}
+	for _, result := range results {
+		title := "Session " + result.SessionID
+		if result.Job != nil && result.Job.Name != "" {
+			title = result.Job.Name
+		}
+		if result.StartTime != 0 {
+			title += ", " + formatEpoch(result.StartTime)
+		}
+
+		for _, url := range result.URLs {
+			itemTitle := title
+			if len(result.URLs) > 1 {
+				itemTitle += " (" + strings.ToUpper(url.Format) + ")"
+			}
+			channel.Items = append(channel.Items, rssItem{
+				Title:   itemTitle,
+				GUID:    rssGUID{Value: result.AccountID + "/" + result.ResourceID + "/" + result.SessionID + "/" + url.Format},
+				PubDate: time.Unix(int64(result.EndTime), 0).UTC().Format(time.RFC1123Z),
+				Content: mediaContent{
+					URL:      url.URL,
+					Type:     mediaTypes[url.Format],
+					Medium:   "video",
+					Duration: result.EndTime - result.StartTime,
+				},
+			})
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err := enc.Encode(rssFeed{Version: "2.0", Media: "http://search.yahoo.com/mrss/", Channel: channel})
+	if err != nil {
+		return fmt.Errorf("error encoding MRSS feed: %w", err)
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// notifier is told about the VOD URLs of a completed run, one entry per
+// resource.
+type notifier interface {
+	notify(ctx context.Context, batch batchResult) error
+}
+
+// notifyFlags configure the notifiers of the commands generating VOD URLs.
+type notifyFlags struct {
+	slackWebhook string
+	email        string
+	postResults  string
+	postSecret   string
+	upload       string
+}
+
+func (f *notifyFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.slackWebhook, "notify-slack", envString("SLACK_WEBHOOK_URL", ""), "post the generated VOD URLs to this Slack incoming webhook when the run completes (env SLACK_WEBHOOK_URL)")
+	fs.StringVar(&f.postResults, "post-results", envString("POST_RESULTS_URL", ""), "POST the results as JSON to this URL when the run completes (env POST_RESULTS_URL)")
+	fs.StringVar(&f.postSecret, "post-results-secret", envString("POST_RESULTS_SECRET", ""), "sign --post-results requests with this HMAC-SHA256 secret (env POST_RESULTS_SECRET)")
+	fs.StringVar(&f.upload, "upload", envString("UPLOAD_URL", ""), "upload the results to this s3://<bucket>/<key> or gs://<bucket>/<key> when the run completes, as CSV when the key ends in .csv and JSON otherwise; the key is a template of {{.Date}}, {{.Time}}, {{.AccountID}} and {{.ResourceID}} (env UPLOAD_URL)")
+	fs.StringVar(&f.email, "notify-email", envString("NOTIFY_EMAIL", ""), "comma-separated addresses to mail the run summary and VOD URLs to through the config file's SMTP server, overrides its recipients (env NOTIFY_EMAIL)")
+}
+
+// notifiers returns the notifiers configured by flag and in the config file
+// loaded by cf.
+func (f *notifyFlags) notifiers(cf *clientFlags) ([]notifier, error) {
+	var notifiers []notifier
+	if f.slackWebhook != "" {
+		notifiers = append(notifiers, &slackNotifier{webhook: f.slackWebhook, httpClient: cf.httpClient()})
+	}
+	if f.postResults != "" {
+		notifiers = append(notifiers, &webhookNotifier{url: f.postResults, secret: f.postSecret, httpClient: cf.httpClient()})
+	} else if f.postSecret != "" {
+		return nil, errors.New("--post-results-secret requires --post-results")
+	}
+
+	if f.upload != "" {
+		n, err := newUploadNotifier(context.Background(), f.upload)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	email := cf.config.Email
+	if f.email != "" {
+		email.To = strings.Split(f.email, ",")
+	}
+	if password := os.Getenv("SMTP_PASSWORD"); password != "" {
+		email.Password = password
+	}
+	if len(email.To) > 0 {
+		n, err := newEmailNotifier(email)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	return notifiers, nil
+}
+
+// notifyAll hands batch to every notifier, without the sessions only listed
+// as expired. A failing notifier is logged but doesn't fail the run, the URLs
+// were generated and printed already.
+func notifyAll(ctx context.Context, notifiers []notifier, batch batchResult) {
+	if len(notifiers) == 0 {
+		return
+	}
+	batch = slices.Clone(batch)
+	for i := range batch {
+		batch[i].Sessions = withoutExpired(batch[i].Sessions)
+	}
+	for _, n := range notifiers {
+		if err := n.notify(ctx, batch); err != nil {
+			slog.WarnContext(ctx, "error sending notification", "err", err)
+		}
+	}
+}
+
+// singleResult wraps the results of a single resource as a batch.
+func singleResult(accountID, resourceID string, results []sessionResult) batchResult {
+	return batchResult{{AccountID: accountID, ResourceID: resourceID, Sessions: results}}
+}
+
+// groupByResource folds session results of several resources into one batch
+// entry per resource.
+func groupByResource(results []sessionResult) batchResult {
+	var batch batchResult
+	index := map[string]int{}
+	for _, result := range results {
+		key := result.AccountID + "/" + result.ResourceID
+		i, ok := index[key]
+		if !ok {
+			i = len(batch)
+			index[key] = i
+			batch = append(batch, inputResult{AccountID: result.AccountID, ResourceID: result.ResourceID})
+		}
+		batch[i].Sessions = append(batch[i].Sessions, result)
+	}
+	return batch
+}
+
+// postJSON POSTs v as JSON to url with the given extra headers, failing on
+// anything but a 2xx.
+func postJSON(ctx context.Context, httpClient *http.Client, url string, v any, headers http.Header) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+	return postBody(ctx, httpClient, url, body, headers)
+}
+
+// postBody POSTs a JSON body to url, failing on anything but a 2xx.
+func postBody(ctx context.Context, httpClient *http.Client, url string, body []byte, headers http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error framing request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	return nil
+}
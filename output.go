@@ -0,0 +1,407 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+	// outputEmbed renders an HTML player snippet per URL, see writeEmbed.
+	outputEmbed = "embed"
+	// outputMRSS renders the URLs as an MRSS feed, see writeMRSS.
+	outputMRSS = "mrss"
+	// outputJSONL prints a JSON object per URL and line, see jsonlWriter.
+	outputJSONL = "jsonl"
+	// outputTable prints an aligned table with the status of every URL, see
+	// writeTable.
+	outputTable = "table"
+)
+
+// sessionResult is the output record of a single session, holding one URL per
+// requested manifest format.
+type sessionResult struct {
+	SessionID  string `json:"session_id"`
+	ResourceID string `json:"resource_id"`
+	AccountID  string `json:"account_id"`
+	StartTime  int    `json:"start_time"`
+	EndTime    int    `json:"end_time"`
+	// VODExpiresAt is when the session falls out of the VOD window, and
+	// VODRemainingSeconds how long that was away when the URLs were
+	// generated.
+	VODExpiresAt        time.Time `json:"vod_expires_at"`
+	VODRemainingSeconds int64     `json:"vod_remaining_seconds"`
+	// Job is only set when --job-details fetched the live job of the
+	// session.
+	Job *jobDetails `json:"job,omitempty"`
+	// Thumbnail is only set when --thumbnails grabbed a frame of the
+	// session's VOD, the path of the image.
+	Thumbnail string      `json:"thumbnail,omitempty"`
+	URLs      []resultURL `json:"urls"`
+	// Errors are the failures of the session's URLs that could not be
+	// generated.
+	Errors []errorInfo `json:"errors,omitempty"`
+
+	// jobID is the live job the client reported for the session.
+	jobID string
+	// expired marks the sessions skipped as outside the VOD window, only
+	// listed by table output.
+	expired bool
+}
+
+type resultURL struct {
+	Format string `json:"format"`
+	Token  string `json:"token"`
+	URL    string `json:"url"`
+	// MasterURL and Rendition are only set when --max-resolution or
+	// --audio-only replaced the master playlist URL with the URL of a single
+	// rendition.
+	MasterURL string `json:"master_url,omitempty"`
+	Rendition string `json:"rendition,omitempty"`
+	// ExpiresAt is when the playback token of the URL expires.
+	ExpiresAt time.Time `json:"expires_at"`
+	// ShortURL is only set when --shorten created a short link.
+	ShortURL string `json:"short_url,omitempty"`
+	// Verified is only set when --verify fetched the manifest.
+	Verified    *bool  `json:"verified,omitempty"`
+	VerifyError string `json:"verify_error,omitempty"`
+	// Captions are the languages of the caption tracks --verify found, empty
+	// when the manifest has none and nil when they are unknown.
+	Captions []string `json:"captions,omitzero"`
+	// Inspection is only set when --inspect parsed the playlists.
+	Inspection *manifestInfo `json:"inspection,omitempty"`
+}
+
+// groupBySession folds playback URLs, which the client returns grouped by
+// session, into one record per session. vodWindowDays is the VOD window the
+// sessions fall out of.
+func groupBySession(playbackURLs []brightcove.PlaybackURL, vodWindowDays int) []sessionResult {
+	var results []sessionResult
+
+	for _, url := range playbackURLs {
+		if len(results) == 0 || results[len(results)-1].SessionID != url.Session.ID {
+			results = append(results, newSessionResult(url.Session, vodWindowDays))
+		}
+
+		result := &results[len(results)-1]
+		result.URLs = append(result.URLs, resultURL{
+			Format:    url.Format,
+			Token:     url.Token,
+			URL:       url.URL,
+			ExpiresAt: url.ExpiresAt,
+		})
+	}
+
+	return results
+}
+
+// newSessionResult returns the record of session, without URLs.
+func newSessionResult(session brightcove.Session, vodWindowDays int) sessionResult {
+	vodExpiry := session.VODExpiry(vodWindowDays)
+	return sessionResult{
+		SessionID:           session.ID,
+		ResourceID:          session.ResourceID,
+		AccountID:           session.AccountID,
+		StartTime:           session.StartTime,
+		EndTime:             session.EndTime,
+		VODExpiresAt:        vodExpiry,
+		VODRemainingSeconds: int64(time.Until(vodExpiry).Seconds()),
+		jobID:               session.JobID,
+	}
+}
+
+// withoutExpired returns results without the sessions marked as expired.
+func withoutExpired(results []sessionResult) []sessionResult {
+	return slices.DeleteFunc(slices.Clone(results), func(r sessionResult) bool { return r.expired })
+}
+
+// outputOptions decide how results are rendered.
+type outputOptions struct {
+	format   string
+	template *template.Template
+	// quiet prints nothing but the URLs, one per line.
+	quiet bool
+	// feed describes the feed of mrss output.
+	feed feedInfo
+}
+
+func newOutputOptions(format, tmpl string, quiet bool) (outputOptions, error) {
+	switch format {
+	case outputText, outputJSON, outputJSONL, outputEmbed, outputMRSS, outputTable:
+	default:
+		return outputOptions{}, fmt.Errorf("unsupported output %q, expected text, json, jsonl, embed, mrss or table", format)
+	}
+
+	out := outputOptions{format: format, quiet: quiet, feed: feedInfo{title: defaultFeedTitle, link: defaultFeedLink}}
+	if tmpl != "" {
+		// Every URL is rendered on its own line
+		if !strings.HasSuffix(tmpl, "\n") {
+			tmpl += "\n"
+		}
+		t, err := template.New("output").Parse(tmpl)
+		if err != nil {
+			return outputOptions{}, fmt.Errorf("error parsing template: %w", err)
+		}
+		out.template = t
+	}
+
+	return out, nil
+}
+
+func (o outputOptions) writeResults(w io.Writer, results []sessionResult) error {
+	switch {
+	case o.template != nil:
+		return writeTemplate(w, o.template, "", results)
+	case o.quiet:
+		return writeURLs(w, results)
+	case o.format == outputJSON:
+		return writeJSON(w, results)
+	case o.format == outputEmbed:
+		return writeEmbed(w, results)
+	case o.format == outputMRSS:
+		return writeMRSS(w, o.feed, results)
+	case o.format == outputJSONL:
+		return newJSONLWriter(w).writeResults("", results)
+	case o.format == outputTable:
+		return writeTable(w, sessionRows(results))
+	}
+	return writeText(w, results)
+}
+
+// listsExpired reports whether the sessions skipped as outside the VOD window
+// are part of the results.
+func (o outputOptions) listsExpired() bool {
+	return o.format == outputTable && o.template == nil && !o.quiet
+}
+
+// writeError reports the failure of a run in JSON output, shaped like a
+// failed batch input. Other outputs only log it.
+func (o outputOptions) writeError(w io.Writer, playbackURL, accountID, resourceID string, err error) error {
+	if o.template != nil || o.quiet || o.format != outputJSON && o.format != outputJSONL {
+		return nil
+	}
+
+	result := inputResult{PlaybackURL: playbackURL, AccountID: accountID, ResourceID: resourceID}
+	result.setError(err)
+	if o.format == outputJSONL {
+		return json.NewEncoder(w).Encode(result)
+	}
+	return writeJSON(w, result)
+}
+
+// streams reports whether the results of a batch are printed as each input
+// completes rather than once the batch is done.
+func (o outputOptions) streams() bool {
+	return o.format == outputJSONL && o.template == nil && !o.quiet
+}
+
+func (o outputOptions) writeBatch(w io.Writer, batch batchResult) error {
+	switch {
+	case o.template != nil:
+		for _, result := range batch {
+			if err := writeTemplate(w, o.template, result.PlaybackURL, result.Sessions); err != nil {
+				return err
+			}
+		}
+		return nil
+	case o.quiet:
+		for _, result := range batch {
+			if err := writeURLs(w, result.Sessions); err != nil {
+				return err
+			}
+		}
+		return nil
+	case o.format == outputJSON:
+		return writeJSON(w, batch)
+	case o.format == outputEmbed:
+		for _, result := range batch {
+			if err := writeEmbed(w, result.Sessions); err != nil {
+				return err
+			}
+		}
+		return nil
+	case o.format == outputMRSS:
+		// One feed of every resource
+		var results []sessionResult
+		for _, result := range batch {
+			results = append(results, result.Sessions...)
+		}
+		return writeMRSS(w, o.feed, results)
+	case o.format == outputJSONL:
+		jsonl := newJSONLWriter(w)
+		for _, result := range batch {
+			if result.Error != "" {
+				jsonl.writeInput(result)
+			} else if err := jsonl.writeResults(result.PlaybackURL, result.Sessions); err != nil {
+				return err
+			}
+		}
+		return nil
+	case o.format == outputTable:
+		return writeTable(w, batchRows(batch))
+	}
+	return writeBatchText(w, batch)
+}
+
+// templateData is what --template is executed with, once per generated URL.
+type templateData struct {
+	Index       int
+	PlaybackURL string
+	AccountID   string
+	ResourceID  string
+	SessionID   string
+	StartTime   int
+	EndTime     int
+	Start       time.Time
+	End         time.Time
+	Format      string
+	Token       string
+	URL         string
+	ExpiresAt   time.Time
+	ShortURL    string
+	Verified    bool
+	VerifyError string
+	// VODExpiresAt is when the session falls out of the VOD window, and
+	// VODRemaining how long is left until then.
+	VODExpiresAt time.Time
+	VODRemaining time.Duration
+	// JobName and JobState are only set with --job-details.
+	JobName  string
+	JobState string
+}
+
+func writeTemplate(w io.Writer, t *template.Template, playbackURL string, results []sessionResult) error {
+	for i, result := range results {
+		for _, url := range result.URLs {
+			data := templateData{
+				Index:        i,
+				PlaybackURL:  playbackURL,
+				AccountID:    result.AccountID,
+				ResourceID:   result.ResourceID,
+				SessionID:    result.SessionID,
+				StartTime:    result.StartTime,
+				EndTime:      result.EndTime,
+				Start:        time.Unix(int64(result.StartTime), 0).In(displayLocation),
+				End:          time.Unix(int64(result.EndTime), 0).In(displayLocation),
+				Format:       url.Format,
+				Token:        url.Token,
+				URL:          url.URL,
+				ExpiresAt:    url.ExpiresAt,
+				ShortURL:     url.ShortURL,
+				Verified:     url.Verified == nil || *url.Verified,
+				VerifyError:  url.VerifyError,
+				VODExpiresAt: result.VODExpiresAt,
+				VODRemaining: time.Duration(result.VODRemainingSeconds) * time.Second,
+			}
+			if result.Job != nil {
+				data.JobName, data.JobState = result.Job.Name, result.Job.State
+			}
+			if err := t.Execute(w, data); err != nil {
+				return fmt.Errorf("error executing template: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func writeText(w io.Writer, results []sessionResult) error {
+	for i, result := range results {
+		fmt.Fprintln(w)
+		if result.StartTime != 0 {
+			fmt.Fprintf(w, "Session[%d] %s: %s to %s (%s)\n", i, result.SessionID, formatEpoch(result.StartTime), formatEpoch(result.EndTime), formatDuration(result.EndTime-result.StartTime))
+		}
+		if job := result.Job; job != nil {
+			fmt.Fprintf(w, "  job %q (%s, %s)", job.Name, job.ID, job.State)
+			if len(job.Labels) > 0 {
+				fmt.Fprintf(w, " labels: %s", strings.Join(job.Labels, ", "))
+			}
+			fmt.Fprintln(w)
+		}
+		if result.Thumbnail != "" {
+			fmt.Fprintf(w, "  thumbnail: %s\n", result.Thumbnail)
+		}
+		for _, url := range result.URLs {
+			label := fmt.Sprintf("VOD URL[%d]", i)
+			if len(result.URLs) > 1 {
+				label += " " + strings.ToUpper(url.Format)
+			}
+			fmt.Fprintf(w, "%s: %s%s%s\n", label, url.URL, expiryNote(url), verifyNote(url))
+			if url.Rendition != "" {
+				fmt.Fprintf(w, "  rendition %s of %s\n", url.Rendition, url.MasterURL)
+			}
+			if url.ShortURL != "" {
+				fmt.Fprintf(w, "  short link: %s\n", url.ShortURL)
+			}
+			if note := captionsNote(url); note != "" {
+				fmt.Fprintln(w, note)
+			}
+			if note := inspectNote(url.Inspection); note != "" {
+				fmt.Fprintln(w, note)
+			}
+		}
+		for _, failure := range result.Errors {
+			fmt.Fprintf(w, "FAILED %s: %s\n", strings.ToUpper(failure.Format), failure.Message)
+		}
+		if !result.VODExpiresAt.IsZero() {
+			fmt.Fprintf(w, "  VOD window: %s left (until %s)\n", formatDays(time.Duration(result.VODRemainingSeconds)*time.Second), formatTime(result.VODExpiresAt))
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// writeURLs prints the bare URLs, one per line, for shell composition.
+func writeURLs(w io.Writer, results []sessionResult) error {
+	for _, result := range results {
+		for _, url := range result.URLs {
+			if _, err := fmt.Fprintln(w, url.URL); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatDuration renders a duration in seconds to the minute, e.g. 1h42m,
+// or to the second below a minute.
+func formatDuration(seconds int) string {
+	d := time.Duration(seconds) * time.Second
+	if d < time.Minute {
+		return d.String()
+	}
+	s := strings.TrimSuffix(d.Truncate(time.Minute).String(), "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// expiryNote shows when the token of a URL expires in text output.
+func expiryNote(url resultURL) string {
+	if url.ExpiresAt.IsZero() {
+		return ""
+	}
+	return " (expires " + formatTime(url.ExpiresAt) + ")"
+}
+
+// verifyNote flags URLs that failed --verify in text output.
+func verifyNote(url resultURL) string {
+	if url.Verified == nil || *url.Verified {
+		return ""
+	}
+	return fmt.Sprintf(" (UNVERIFIED: %s)", url.VerifyError)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
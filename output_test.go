@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcovetest"
+)
+
+const (
+	testAccountID  = "200"
+	testResourceID = "100"
+)
+
+// generateTestResults generates the HLS and DASH URLs of two ended sessions
+// and a session outside the VOD window with the fake API, the way the
+// generate command does for out.
+func generateTestResults(t *testing.T, out outputOptions) []sessionResult {
+	t.Helper()
+	srv := brightcovetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	now := int(time.Now().Unix())
+	for i, end := range []int{now - 30*86400, now - 7200, now - 3600} {
+		srv.AddSession(brightcove.Session{ID: strconv.Itoa(i + 1), AccountID: testAccountID, ResourceID: testResourceID, StartTime: end - 1800, EndTime: end})
+	}
+
+	opts := generateOptions{
+		formats:     []string{brightcove.ManifestFormatHLS, brightcove.ManifestFormatDASH},
+		selection:   sessionSelection{index: -1},
+		summary:     &runSummary{},
+		listExpired: out.listsExpired(),
+	}
+	results, err := generate(context.Background(), srv.NewClient(), testAccountID, testResourceID, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
+
+func TestOutputWriters(t *testing.T) {
+	displayLocation = time.UTC
+
+	tests := []struct {
+		format string
+		write  func(out outputOptions, w *bytes.Buffer, results []sessionResult) error
+		check  func(t *testing.T, output string)
+	}{
+		{
+			format: outputJSON,
+			check: func(t *testing.T, output string) {
+				var results []sessionResult
+				if err := json.Unmarshal([]byte(output), &results); err != nil {
+					t.Fatal(err)
+				}
+				if len(results) != 2 {
+					t.Fatalf("got %d sessions, want the 2 inside the VOD window", len(results))
+				}
+				for _, result := range results {
+					if len(result.URLs) != 2 || result.URLs[0].Format != brightcove.ManifestFormatHLS || result.URLs[1].Format != brightcove.ManifestFormatDASH {
+						t.Errorf("session %s: got URLs %+v, want HLS then DASH", result.SessionID, result.URLs)
+					}
+				}
+			},
+		},
+		{
+			format: outputJSONL,
+			check: func(t *testing.T, output string) {
+				var n int
+				scanner := bufio.NewScanner(strings.NewReader(output))
+				for scanner.Scan() {
+					var record urlRecord
+					if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+						t.Fatalf("line %d: %v", n, err)
+					}
+					if record.SessionID == "" || record.ResourceID != testResourceID || record.URL == "" {
+						t.Errorf("line %d: got %s, want a URL with its session", n, scanner.Text())
+					}
+					n++
+				}
+				if n != 4 {
+					t.Errorf("got %d lines, want one per URL", n)
+				}
+			},
+		},
+		{
+			format: outputTable,
+			check: func(t *testing.T, output string) {
+				lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+				if len(lines) != 6 {
+					t.Fatalf("got %d lines, want a header, a skipped session and 4 URLs:\n%s", len(lines), output)
+				}
+				if !strings.HasPrefix(lines[0], "STATUS ") {
+					t.Errorf("got header %q", lines[0])
+				}
+				var statuses []string
+				for _, line := range lines[1:] {
+					statuses = append(statuses, strings.Fields(line)[0])
+				}
+				want := []string{statusSkippedExpired, statusGenerated, statusGenerated, statusGenerated, statusGenerated}
+				if !slices.Equal(statuses, want) {
+					t.Errorf("got statuses %v, want %v", statuses, want)
+				}
+				// Columns line up and nothing is colored off a terminal
+				column := strings.Index(lines[0], "URL")
+				for _, line := range lines[2:] {
+					if !strings.HasPrefix(line[column:], "http") {
+						t.Errorf("URL column not aligned: %q", line)
+					}
+				}
+				if strings.Contains(output, "\x1b[") {
+					t.Errorf("got colors writing to a buffer:\n%q", output)
+				}
+			},
+		},
+		{
+			format: "csv",
+			write: func(out outputOptions, w *bytes.Buffer, results []sessionResult) error {
+				return writeCSV(w, singleResult(testAccountID, testResourceID, results))
+			},
+			check: func(t *testing.T, output string) {
+				records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(records) != 5 || !slices.Equal(records[0], csvHeader) {
+					t.Fatalf("got %v, want the header and a row per URL", records)
+				}
+				for _, record := range records[1:] {
+					if record[0] != testAccountID || record[5] == "" || !strings.HasPrefix(record[6], "http") {
+						t.Errorf("got row %v", record)
+					}
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			format := tt.format
+			if tt.write != nil {
+				format = outputText
+			}
+			out, err := newOutputOptions(format, "", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := generateTestResults(t, out)
+
+			var buf bytes.Buffer
+			if tt.write != nil {
+				err = tt.write(out, &buf, results)
+			} else {
+				err = out.writeResults(&buf, results)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, buf.String())
+		})
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []tableRow
+		lines int
+	}{
+		{name: "no rows", lines: 1},
+		{
+			name: "multi-line note",
+			rows: []tableRow{
+				{status: statusFailed, resourceID: "-", sessionID: "-", start: "-", duration: "-", format: "-", url: "https://x", note: "<html>\r\n<body>502\tBad Gateway</body>\n</html>"},
+				{status: statusGenerated, resourceID: "1", sessionID: "2", start: "-", duration: "-", format: "hls", url: "https://y"},
+			},
+			lines: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeTable(&buf, tt.rows); err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if len(lines) != tt.lines {
+				t.Fatalf("got %d lines, want %d:\n%s", len(lines), tt.lines, buf.String())
+			}
+			for _, line := range lines {
+				if strings.ContainsAny(line, "\r\t") || strings.HasSuffix(line, " ") {
+					t.Errorf("got line %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONLBatchErrors(t *testing.T) {
+	var failed inputResult
+	failed.PlaybackURL = "https://players.brightcove.net/200/default_default/index.html?videoId=100"
+	failed.setError(brightcove.ErrVODWindowExpired)
+
+	tests := []struct {
+		name  string
+		write func(w *bytes.Buffer) error
+	}{
+		{
+			name: "batch",
+			write: func(w *bytes.Buffer) error {
+				out, err := newOutputOptions(outputJSONL, "", false)
+				if err != nil {
+					return err
+				}
+				return out.writeBatch(w, batchResult{failed})
+			},
+		},
+		{
+			name: "stream",
+			write: func(w *bytes.Buffer) error {
+				newJSONLWriter(w).writeInput(failed)
+				return nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			var record inputResult
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+			if record.Error == "" || record.ErrorInfo == nil || record.ErrorInfo.Code != failed.ErrorInfo.Code {
+				t.Errorf("got %s, want the error with its error_info", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// overlappingSessions returns the groups of ended sessions whose time ranges
+// overlap, e.g. the sessions of redundant jobs recording the same content.
+// Sessions must be sorted by start time, live ones are ignored.
+func overlappingSessions(sessions []brightcove.Session) [][]brightcove.Session {
+	var groups [][]brightcove.Session
+	var group []brightcove.Session
+	var end int
+	for _, session := range sessions {
+		if session.EndTime == 0 {
+			continue
+		}
+		if len(group) > 0 && session.StartTime < end {
+			group = append(group, session)
+			end = max(end, session.EndTime)
+			continue
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+		group = []brightcove.Session{session}
+		end = session.EndTime
+	}
+	if len(group) > 1 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// checkOverlaps warns about overlapping sessions and, with dedupe, keeps only
+// the longest session of each group. It returns the sessions to generate and
+// how many were dropped.
+func checkOverlaps(ctx context.Context, sessions *brightcove.Sessions, dedupe bool) (*brightcove.Sessions, int) {
+	groups := overlappingSessions(sessions.Events)
+	if len(groups) == 0 {
+		return sessions, 0
+	}
+
+	dropped := map[string]bool{}
+	for _, group := range groups {
+		ids := make([]string, len(group))
+		longest := group[0]
+		for i, session := range group {
+			ids[i] = session.ID
+			if session.EndTime-session.StartTime > longest.EndTime-longest.StartTime {
+				longest = session
+			}
+		}
+		if !dedupe {
+			slog.WarnContext(ctx, "sessions overlap, possibly recordings of the same content, see --dedupe-overlaps", "session_ids", ids)
+			continue
+		}
+		slog.WarnContext(ctx, "sessions overlap, keeping the longest one", "session_ids", ids, "kept_session_id", longest.ID)
+		for _, session := range group {
+			if session.ID != longest.ID {
+				dropped[session.ID] = true
+			}
+		}
+	}
+	if len(dropped) == 0 {
+		return sessions, 0
+	}
+
+	deduped := &brightcove.Sessions{}
+	for _, session := range sessions.Events {
+		if !dropped[session.ID] {
+			deduped.Events = append(deduped.Events, session)
+		}
+	}
+	return deduped, len(dropped)
+}
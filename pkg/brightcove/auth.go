@@ -0,0 +1,162 @@
+package brightcove
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// tokenExpiryMargin is how long before its expiry a token stops being reused,
+// so it doesn't run out in the middle of a run.
+const tokenExpiryMargin = time.Minute
+
+// Token is an OAuth access token returned by the client credentials grant.
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresIn   int       `json:"expires_in"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Valid reports whether the token can still be used for a while.
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && time.Until(t.ExpiresAt) > tokenExpiryMargin
+}
+
+// AccessToken returns a valid access token, reusing the last one the client
+// obtained or the one in its TokenCache when they haven't expired yet, and
+// requesting a new one otherwise.
+func (c *Client) AccessToken(ctx context.Context) (*Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token.Valid() {
+		return c.token, nil
+	}
+
+	if c.TokenCache != nil {
+		token, err := c.TokenCache.Get(c.cacheKey())
+		if err != nil {
+			c.Logger.WarnContext(ctx, "ignoring token cache", "err", err)
+		} else if token.Valid() {
+			c.token = token
+			return token, nil
+		}
+	}
+
+	token, err := c.GenerateToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.token = token
+
+	if c.TokenCache != nil {
+		if err := c.TokenCache.Put(c.cacheKey(), token); err != nil {
+			c.Logger.WarnContext(ctx, "error caching access token", "err", err)
+		}
+	}
+
+	return token, nil
+}
+
+// refreshToken replaces a token the API rejected with a newly requested one.
+// If another call already replaced it, the replacement is returned instead.
+func (c *Client) refreshToken(ctx context.Context, rejected *Token) (*Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != rejected && c.token.Valid() {
+		return c.token, nil
+	}
+
+	token, err := c.GenerateToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.token = token
+
+	if c.TokenCache != nil {
+		if err := c.TokenCache.Put(c.cacheKey(), token); err != nil {
+			c.Logger.WarnContext(ctx, "error caching access token", "err", err)
+		}
+	}
+
+	return token, nil
+}
+
+// Credentials are OAuth client credentials.
+type Credentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// CredentialsProvider supplies client credentials, e.g. from a secret store.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// credentials returns the client credentials, fetching them from the
+// Credentials provider when there is one.
+func (c *Client) credentials(ctx context.Context) (Credentials, error) {
+	c.credMu.Lock()
+	defer c.credMu.Unlock()
+
+	if c.Credentials != nil {
+		creds, err := c.Credentials.Credentials(ctx)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("error fetching client credentials: %w", err)
+		}
+		if c.clientID != "" && (creds.ClientID != c.clientID || creds.ClientSecret != c.clientSecret) {
+			c.Logger.InfoContext(ctx, "client credentials rotated", "client_id", creds.ClientID)
+		}
+		c.clientID, c.clientSecret = creds.ClientID, creds.ClientSecret
+	}
+
+	return Credentials{ClientID: c.clientID, ClientSecret: c.clientSecret}, nil
+}
+
+// cacheKey is the client ID tokens are cached under.
+func (c *Client) cacheKey() string {
+	c.credMu.Lock()
+	defer c.credMu.Unlock()
+	return c.clientID
+}
+
+// GenerateToken exchanges the client credentials for an access token.
+func (c *Client) GenerateToken(ctx context.Context) (_ *Token, err error) {
+	ctx, span := tracer.Start(ctx, "brightcove.GenerateToken")
+	defer func() { endSpan(span, err) }()
+
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	encodedCredentials := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", creds.ClientID, creds.ClientSecret)))
+
+	url := c.oauthURL() + "/v4/access_token"
+	payload := []byte("grant_type=client_credentials")
+	headers := http.Header{
+		"Content-Type":  {"application/x-www-form-urlencoded"},
+		"Authorization": {"Basic " + encodedCredentials},
+	}
+
+	body, err := c.doRequest(ctx, http.MethodPost, url, payload, headers)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.Status == http.StatusBadRequest || apiErr.Status == http.StatusUnauthorized || apiErr.Status == http.StatusForbidden) {
+		return nil, fmt.Errorf("%w: %w", ErrAuthentication, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var token Token
+	if err = json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("error decoding body: %w", err)
+	}
+	token.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+
+	return &token, nil
+}
@@ -0,0 +1,56 @@
+package brightcove
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestTokenRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		// rejections is how many calls in a row the API answers with a 401.
+		rejections int
+		wantTokens int
+		wantCalls  int
+		wantErr    bool
+	}{
+		{name: "accepted", wantTokens: 1, wantCalls: 1},
+		{name: "rejected once", rejections: 1, wantTokens: 2, wantCalls: 2},
+		{name: "rejected again", rejections: 2, wantTokens: 2, wantCalls: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokens, calls int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/v4/access_token" {
+					tokens++
+					json.NewEncoder(w).Encode(Token{AccessToken: "token-" + strconv.Itoa(tokens), ExpiresIn: 300})
+					return
+				}
+				calls++
+				if calls <= tt.rejections {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if got, want := r.Header.Get("Authorization"), "Bearer token-"+strconv.Itoa(tokens); got != want {
+					t.Errorf("got Authorization %q, want %q", got, want)
+				}
+				w.Write([]byte("{}"))
+			}))
+			defer srv.Close()
+			c := newTestClient(srv)
+
+			_, err := c.doAuthorizedRequest(context.Background(), http.MethodGet, "https://api.live.brightcove.com/v2/test", nil, http.Header{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if tokens != tt.wantTokens || calls != tt.wantCalls {
+				t.Errorf("got %d tokens and %d calls, want %d and %d", tokens, calls, tt.wantTokens, tt.wantCalls)
+			}
+		})
+	}
+}
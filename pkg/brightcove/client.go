@@ -0,0 +1,354 @@
+// Package brightcove is a small client for the Brightcove OAuth and Live APIs
+// used to turn NextGenLive sessions into VOD playback URLs.
+package brightcove
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"sync"
+	"time"
+)
+
+const (
+	ManifestFormatHLS  = "hls"
+	ManifestFormatDASH = "dash"
+
+	// VODWindowDuration is the default number of days after a session ends
+	// during which VOD URLs can be generated for it.
+	VODWindowDuration = 14
+
+	// DefaultMaxResponseBytes is the default size limit of API response
+	// bodies.
+	DefaultMaxResponseBytes = 32 << 20
+)
+
+// Client talks to the Brightcove APIs on behalf of a single set of client credentials.
+type Client struct {
+	// Retry is the policy applied to every API call.
+	Retry RetryPolicy
+	// RateLimiter, when set, paces every API call including retries.
+	RateLimiter *RateLimiter
+	// TokenCache, when set, lets AccessToken reuse tokens across runs.
+	TokenCache TokenCache
+	// SessionCache, when set, lets GetResourceSessions reuse recently
+	// fetched sessions lists.
+	SessionCache *SessionCache
+	// VODWindowDays is how many days after a session ends VOD URLs are
+	// still generated for it.
+	VODWindowDays int
+	// MaxSessions caps how many sessions GetResourceSessions collects across
+	// pages, 0 means all of them.
+	MaxSessions int
+	// PlaybackTokenTTL, when set, is the lifetime requested for playback
+	// tokens. 0 leaves it to the API.
+	PlaybackTokenTTL time.Duration
+	// AdConfigID, when set, is the server-side ad insertion configuration the
+	// VOD playback URLs are requested with, for accounts using SSAI.
+	AdConfigID string
+	// PlaybackAuth, when set, supplies the playback authorization JWT sent
+	// with every playback URL request and appended to the VOD URLs as
+	// bcov_auth, for accounts enforcing Playback Rights.
+	PlaybackAuth PlaybackAuthorizer
+	// URLParams are query parameters appended to every VOD playback URL,
+	// e.g. analytics tags. Parameters the URL already has are kept.
+	URLParams map[string]string
+	// Concurrency is how many playback token and URL requests are issued in
+	// parallel. Results keep their order regardless. 0 or 1 means one at a
+	// time.
+	Concurrency int
+	// SkipLive makes GeneratePlaybackTokens skip a live session instead of
+	// refusing to generate tokens for the ended sessions of the resource. The
+	// API may still reject the requests while the resource is live.
+	SkipLive bool
+	// ContinueOnError makes GeneratePlaybackTokens and GeneratePlaybackURLs
+	// carry on when the call for one session fails. The results of the other
+	// sessions are returned along with a *PartialError.
+	ContinueOnError bool
+	// Credentials, when set, supplies the client credentials every time a new
+	// access token is requested, so rotated secrets are picked up without a
+	// restart. The credentials given with WithCredentials are used until then.
+	Credentials CredentialsProvider
+	// BaseURLs override the roots of the APIs the client calls.
+	BaseURLs BaseURLs
+	// Region is the region of the live resources whose region wasn't recorded
+	// with SetResourceRegion, used with BaseURLs.RegionalLive.
+	Region string
+	// Observer, when set, is notified of every API call and retry.
+	Observer Observer
+	// UserAgent, when set, is the User-Agent header of every API call.
+	UserAgent string
+	// MaxResponseBytes caps the size of API response bodies,
+	// DefaultMaxResponseBytes when 0. Larger responses fail with
+	// ErrResponseTooLarge instead of being held in memory.
+	MaxResponseBytes int64
+	// Logger receives the client's diagnostics. Credentials and tokens are
+	// redacted from everything it logs.
+	Logger *slog.Logger
+
+	doer    Doer
+	timeout time.Duration
+
+	credMu       sync.Mutex
+	clientID     string
+	clientSecret string
+
+	mu    sync.Mutex
+	token *Token
+
+	regionMu sync.Mutex
+	regions  map[string]string
+
+	failoverMu     sync.Mutex
+	liveActive     int
+	liveFailedOver time.Time
+}
+
+// NewClient returns a Client configured by opts. Credentials are given with
+// WithCredentials or WithCredentialsProvider.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		Retry:         DefaultRetryPolicy(),
+		VODWindowDays: VODWindowDuration,
+		Logger:        slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	httpClient, isHTTPClient := c.doer.(*http.Client)
+	switch {
+	case c.doer == nil:
+		c.doer = &http.Client{Timeout: cmp.Or(c.timeout, defaultTimeout)}
+	case isHTTPClient && c.timeout > 0:
+		withTimeout := *httpClient
+		withTimeout.Timeout = c.timeout
+		c.doer = &withTimeout
+	}
+
+	return c
+}
+
+// decodeFunc consumes the body of a 200 response. It is called again for
+// every retried attempt, so it must start over each time.
+type decodeFunc func(body io.Reader) error
+
+// readBody returns a decodeFunc keeping the whole body in body.
+func readBody(body *[]byte) decodeFunc {
+	return func(r io.Reader) (err error) {
+		*body, err = io.ReadAll(r)
+		return err
+	}
+}
+
+// doAuthorizedRequest performs an API call authorized with the client's access
+// token and returns the body of a 200 response, see doAuthorizedDecode.
+func (c *Client) doAuthorizedRequest(ctx context.Context, method, url string, payload []byte, headers http.Header) ([]byte, error) {
+	var body []byte
+	err := c.doAuthorizedDecode(ctx, method, url, payload, headers, readBody(&body))
+	return body, err
+}
+
+// doAuthorizedDecode performs an API call authorized with the client's access
+// token, consuming the body of a 200 response with decode. If the API rejects
+// the token with a 401, a new token is requested once and the call is retried
+// with it.
+func (c *Client) doAuthorizedDecode(ctx context.Context, method, url string, payload []byte, headers http.Header, decode decodeFunc) error {
+	token, err := c.AccessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting access token: %w", err)
+	}
+
+	headers = headers.Clone()
+	headers.Set("Authorization", "Bearer "+token.AccessToken)
+
+	err = c.doDecode(ctx, method, url, payload, headers, decode)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		return err
+	}
+
+	c.Logger.InfoContext(ctx, "access token rejected, requesting a new one", "url", redactURL(url))
+	token, err = c.refreshToken(ctx, token)
+	if err != nil {
+		return fmt.Errorf("error refreshing access token: %w", err)
+	}
+	headers.Set("Authorization", "Bearer "+token.AccessToken)
+
+	return c.doDecode(ctx, method, url, payload, headers, decode)
+}
+
+// doRequest performs an API call, retrying it according to the client's retry
+// policy, and returns the body of a 200 response.
+func (c *Client) doRequest(ctx context.Context, method, url string, payload []byte, headers http.Header) ([]byte, error) {
+	var body []byte
+	err := c.doDecode(ctx, method, url, payload, headers, readBody(&body))
+	return body, err
+}
+
+// doDecode performs an API call, retrying it according to the client's retry
+// policy, and consumes the body of a 200 response with decode. A Live API call
+// that can't reach its root fails over to the next of
+// BaseURLs.LiveFallbacks right away, without using up an attempt.
+func (c *Client) doDecode(ctx context.Context, method, url string, payload []byte, headers http.Header, decode decodeFunc) error {
+	roots, current, rest := c.liveTarget(url)
+	if roots != nil {
+		url = roots[current] + rest
+	}
+
+	for attempt := 1; ; attempt++ {
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		retry, retryAfter, err := c.doAttempt(ctx, method, url, payload, headers, decode)
+		if err == nil {
+			return nil
+		}
+		if retry && current+1 < len(roots) && unreachable(err) && ctx.Err() == nil {
+			current++
+			c.failOver(current)
+			c.Logger.WarnContext(ctx, "Live API unreachable, failing over", "method", method, "from", roots[current-1], "to", roots[current], "err", err)
+			url = roots[current] + rest
+			attempt--
+			continue
+		}
+		if !retry || attempt >= c.Retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		if c.Observer != nil {
+			c.Observer.ObserveRetry(endpointName(method, c.defaultRoot(url)))
+		}
+		delay := c.Retry.backoff(attempt, retryAfter)
+		c.Logger.WarnContext(ctx, "retrying API call", "method", method, "url", redactURL(url), "delay", delay.Round(time.Millisecond), "attempt", attempt, "err", err)
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// doAttempt performs a single API call. It reports whether a failure is worth
+// retrying and how long the API asked us to wait before doing so.
+func (c *Client) doAttempt(ctx context.Context, method, url string, payload []byte, headers http.Header, decode decodeFunc) (bool, time.Duration, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return false, 0, fmt.Errorf("error framing request: %w", err)
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v[0])
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	endpoint := endpointName(method, c.defaultRoot(url))
+	idempotent := idempotentCall(method, endpoint)
+
+	start := time.Now()
+	resp, err := c.doer.Do(req)
+	if c.Observer != nil {
+		status := 0
+		if err == nil {
+			status = resp.StatusCode
+		}
+		c.Observer.ObserveRequest(endpoint, status, time.Since(start))
+	}
+	if err != nil {
+		// Transport errors quote the URL, keep playback tokens out of them
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactURL(urlErr.URL)
+		}
+		return idempotent || notSent(err), 0, fmt.Errorf("error getting response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	c.Logger.DebugContext(ctx, "API call",
+		"method", method,
+		"url", redactURL(url),
+		"headers", redactHeaders(req.Header),
+		"status", resp.StatusCode,
+		"duration", time.Since(start),
+	)
+
+	limited := &limitedBody{r: resp.Body, left: c.maxResponseBytes()}
+	if resp.StatusCode != http.StatusOK {
+		respBody, err := io.ReadAll(limited)
+		if limited.tooLarge {
+			// The start of an oversized error is still worth reporting
+			err = nil
+		}
+		if err != nil {
+			return idempotent, 0, fmt.Errorf("error reading body: %w", err)
+		}
+		err = newAPIError(resp.StatusCode, respBody)
+		// A rate limited call was turned away before it was processed
+		retry := retryableStatus(resp.StatusCode) && (idempotent || resp.StatusCode == http.StatusTooManyRequests)
+		return retry, parseRetryAfter(resp.Header.Get("Retry-After")), err
+	}
+
+	if err := decode(limited); err != nil {
+		// Decoders can report a truncated body as malformed
+		if limited.tooLarge {
+			err = ErrResponseTooLarge
+		}
+		// Only a connection dropped while reading is worth another attempt
+		return idempotent && limited.readErr != nil, 0, fmt.Errorf("error reading body: %w", err)
+	}
+	return false, 0, nil
+}
+
+// maxResponseBytes returns the size limit of response bodies.
+func (c *Client) maxResponseBytes() int64 {
+	if c.MaxResponseBytes > 0 {
+		return c.MaxResponseBytes
+	}
+	return DefaultMaxResponseBytes
+}
+
+// limitedBody reads a response body, failing with ErrResponseTooLarge once
+// it is found to hold more than left bytes. It records why reading failed,
+// so decoding errors can be told apart from network errors.
+type limitedBody struct {
+	r    io.Reader
+	left int64
+
+	tooLarge bool
+	readErr  error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.tooLarge {
+		return 0, ErrResponseTooLarge
+	}
+	// Read one byte past the limit to tell a body of exactly left bytes
+	// from a larger one
+	if int64(len(p)) > b.left+1 {
+		p = p[:b.left+1]
+	}
+	n, err := b.r.Read(p)
+	if int64(n) > b.left {
+		n, b.left, b.tooLarge = int(b.left), 0, true
+		return n, ErrResponseTooLarge
+	}
+	b.left -= int64(n)
+	if err != nil && err != io.EOF {
+		b.readErr = err
+	}
+	return n, err
+}
@@ -0,0 +1,239 @@
+package brightcove_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcovetest"
+)
+
+const (
+	accountID  = "200"
+	resourceID = "100"
+)
+
+// fastRetries retries like the default policy without waiting.
+var fastRetries = brightcove.WithRetryPolicy(brightcove.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+// addSessions adds n ended sessions of an hour to the resource, the last one
+// ending an hour ago.
+func addSessions(srv *brightcovetest.Server, n int) {
+	now := int(time.Now().Unix())
+	for i := range n {
+		end := now - 3600 - (n-1-i)*7200
+		srv.AddSession(brightcove.Session{ID: strconv.Itoa(i + 1), AccountID: accountID, ResourceID: resourceID, StartTime: end - 3600, EndTime: end})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		status   int
+		times    int
+		// requests is how many calls of endpoint the server must get.
+		requests int
+		wantErr  bool
+	}{
+		{name: "token rate limited", endpoint: brightcovetest.EndpointPlaybackToken, status: http.StatusTooManyRequests, times: 1, requests: 2},
+		{name: "token server error", endpoint: brightcovetest.EndpointPlaybackToken, status: http.StatusServiceUnavailable, times: 1, requests: 1, wantErr: true},
+		{name: "token bad gateway", endpoint: brightcovetest.EndpointPlaybackToken, status: http.StatusBadGateway, times: 1, requests: 1, wantErr: true},
+		{name: "sessions server error", endpoint: brightcovetest.EndpointSessions, status: http.StatusServiceUnavailable, times: 2, requests: 3},
+		{name: "sessions attempts exhausted", endpoint: brightcovetest.EndpointSessions, status: http.StatusServiceUnavailable, times: 3, requests: 3, wantErr: true},
+		{name: "sessions not found", endpoint: brightcovetest.EndpointSessions, status: http.StatusNotFound, times: 1, requests: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := brightcovetest.NewServer()
+			defer srv.Close()
+			addSessions(srv, 1)
+			srv.Fail(tt.endpoint, tt.status, "", tt.times)
+			client := srv.NewClient(fastRetries)
+
+			sessions, err := client.GetResourceSessions(context.Background(), accountID, resourceID)
+			if err == nil {
+				_, err = client.GeneratePlaybackTokens(context.Background(), sessions)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got := srv.Requests(tt.endpoint); got != tt.requests {
+				t.Errorf("got %d calls of %s, want %d", got, tt.endpoint, tt.requests)
+			}
+		})
+	}
+}
+
+func TestPagination(t *testing.T) {
+	tests := []struct {
+		name        string
+		sessions    int
+		pageSize    int
+		maxSessions int
+		want        int
+		wantPages   int
+	}{
+		{name: "single page", sessions: 5, want: 5, wantPages: 1},
+		{name: "full pages", sessions: 6, pageSize: 2, want: 6, wantPages: 3},
+		{name: "partial last page", sessions: 5, pageSize: 2, want: 5, wantPages: 3},
+		{name: "capped", sessions: 5, pageSize: 2, maxSessions: 3, want: 3, wantPages: 2},
+		{name: "capped within a page", sessions: 5, maxSessions: 2, want: 2, wantPages: 1},
+		{name: "empty", want: 0, wantPages: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := brightcovetest.NewServer()
+			defer srv.Close()
+			srv.PageSize = tt.pageSize
+			addSessions(srv, tt.sessions)
+			client := srv.NewClient()
+			client.MaxSessions = tt.maxSessions
+
+			sessions, err := client.GetResourceSessions(context.Background(), accountID, resourceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sessions.Events) != tt.want {
+				t.Errorf("got %d sessions, want %d", len(sessions.Events), tt.want)
+			}
+			for i := 1; i < len(sessions.Events); i++ {
+				if sessions.Events[i-1].StartTime > sessions.Events[i].StartTime {
+					t.Errorf("sessions not sorted by start time: %+v", sessions.Events)
+				}
+			}
+			if got := srv.Requests(brightcovetest.EndpointSessions); got != tt.wantPages {
+				t.Errorf("got %d pages, want %d", got, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxBytes int64
+		pageSize int
+		wantErr  error
+	}{
+		{name: "under the limit", maxBytes: 1 << 20},
+		{name: "over the limit", maxBytes: 256, wantErr: brightcove.ErrResponseTooLarge},
+		{name: "every page under the limit", maxBytes: 512, pageSize: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := brightcovetest.NewServer()
+			defer srv.Close()
+			srv.PageSize = tt.pageSize
+			addSessions(srv, 10)
+			client := srv.NewClient(brightcove.WithMaxResponseBytes(tt.maxBytes))
+
+			sessions, err := client.GetResourceSessions(context.Background(), accountID, resourceID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && len(sessions.Events) != 10 {
+				t.Errorf("got %d sessions, want 10", len(sessions.Events))
+			}
+		})
+	}
+}
+
+func TestLiveFailover(t *testing.T) {
+	srv := brightcovetest.NewServer()
+	defer srv.Close()
+	addSessions(srv, 1)
+	down := unreachableURL(t)
+
+	tests := []struct {
+		name      string
+		live      string
+		fallbacks []string
+		wantErr   bool
+	}{
+		{name: "primary up", live: srv.URL, fallbacks: []string{down}},
+		{name: "first fallback", live: down, fallbacks: []string{srv.URL}},
+		{name: "second fallback", live: down, fallbacks: []string{down, srv.URL}},
+		{name: "every root down", live: down, fallbacks: []string{down}, wantErr: true},
+		{name: "no fallbacks", live: down, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := brightcove.NewClient(
+				brightcove.WithCredentials(srv.ClientID, srv.ClientSecret),
+				brightcove.WithHTTPClient(srv.Server.Client()),
+				brightcove.WithBaseURLs(brightcove.BaseURLs{OAuth: srv.URL, Live: tt.live, LiveFallbacks: tt.fallbacks}),
+				brightcove.WithRetryPolicy(brightcove.RetryPolicy{MaxAttempts: 1}),
+			)
+
+			// The second call goes straight to the root the first one failed
+			// over to
+			for range 2 {
+				_, err := client.GetResourceSessions(context.Background(), accountID, resourceID)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+// unreachableURL returns the URL of a local port nothing listens on.
+func unreachableURL(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return "http://" + addr
+}
+
+func TestTokenRefresh(t *testing.T) {
+	tests := []struct {
+		name       string
+		revoke     bool
+		failTokens int
+		wantTokens int
+		// wantCalls counts the sessions calls, the rejected one included.
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "cached token", wantTokens: 1, wantCalls: 2},
+		{name: "revoked token", revoke: true, wantTokens: 2, wantCalls: 3},
+		{name: "refresh rejected", revoke: true, failTokens: 1, wantTokens: 2, wantCalls: 2, wantErr: brightcove.ErrAuthentication},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := brightcovetest.NewServer()
+			defer srv.Close()
+			addSessions(srv, 1)
+			client := srv.NewClient(fastRetries)
+
+			ctx := context.Background()
+			if _, err := client.GetResourceSessions(ctx, accountID, resourceID); err != nil {
+				t.Fatal(err)
+			}
+			if tt.revoke {
+				srv.RevokeTokens()
+			}
+			srv.Fail(brightcovetest.EndpointAccessToken, http.StatusUnauthorized, `{"error":"invalid_client"}`, tt.failTokens)
+
+			_, err := client.GetResourceSessions(ctx, accountID, resourceID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if got := srv.Requests(brightcovetest.EndpointAccessToken); got != tt.wantTokens {
+				t.Errorf("got %d access token requests, want %d", got, tt.wantTokens)
+			}
+			if got := srv.Requests(brightcovetest.EndpointSessions); got != tt.wantCalls {
+				t.Errorf("got %d sessions calls, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
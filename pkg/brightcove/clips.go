@@ -0,0 +1,179 @@
+package brightcove
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ClipRequest describes a permanent Video Cloud VOD to create from part of a
+// live resource.
+type ClipRequest struct {
+	// Label identifies the clip job, defaults to the session ID.
+	Label string
+	// Video holds the name, tags and custom fields of the resulting Video
+	// Cloud video.
+	Video Video
+	// StartTime and EndTime are epoch seconds, default to the session's.
+	StartTime int
+	EndTime   int
+}
+
+// Clip is a Live API clip job turning part of a live resource into a Video
+// Cloud video.
+type Clip struct {
+	ID         string `json:"jvod_id"`
+	Label      string `json:"label"`
+	ResourceID string `json:"resource_id"`
+	// JobID is the live job the clip was cut from, set by ListJobClips.
+	JobID     string `json:"job_id,omitempty"`
+	State     string `json:"state"`
+	VideoID   string `json:"video_id"`
+	StartTime int    `json:"stream_start_time"`
+	EndTime   int    `json:"stream_end_time"`
+}
+
+// CreateClip creates a permanent Video Cloud video from a session, so the
+// recording outlives the VOD window.
+func (c *Client) CreateClip(ctx context.Context, session Session, req ClipRequest) (*Clip, error) {
+	if req.Label == "" {
+		req.Label = session.ID
+	}
+	if req.StartTime == 0 {
+		req.StartTime = session.StartTime
+	}
+	if req.EndTime == 0 {
+		req.EndTime = session.EndTime
+	}
+
+	type videoCloudOutput struct {
+		Video Video `json:"video"`
+	}
+	type output struct {
+		Label           string           `json:"label"`
+		StreamStartTime int              `json:"stream_start_time"`
+		StreamEndTime   int              `json:"stream_end_time"`
+		VideoCloud      videoCloudOutput `json:"videocloud"`
+	}
+	data := struct {
+		ResourceID string   `json:"resource_id"`
+		Outputs    []output `json:"outputs"`
+	}{
+		ResourceID: session.ResourceID,
+		Outputs: []output{{
+			Label:           req.Label,
+			StreamStartTime: req.StartTime,
+			StreamEndTime:   req.EndTime,
+			VideoCloud:      videoCloudOutput{Video: req.Video},
+		}},
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v2/accounts/%s/vods", c.liveURL(session.ResourceID), session.AccountID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	body, err := c.doAuthorizedRequest(ctx, http.MethodPost, url, payload, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		VODJobs []Clip `json:"vod_jobs"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("error decoding body: %w", err)
+	}
+	if len(resp.VODJobs) == 0 {
+		return nil, fmt.Errorf("no clip job returned for session %s", session.ID)
+	}
+
+	clip := resp.VODJobs[0]
+	if clip.ResourceID == "" {
+		clip.ResourceID = session.ResourceID
+	}
+
+	// Make sure the metadata sticks when the video already exists
+	if clip.VideoID != "" && (len(req.Video.Tags) > 0 || len(req.Video.CustomFields) > 0) {
+		if _, err := c.UpdateVideo(ctx, session.AccountID, clip.VideoID, req.Video); err != nil {
+			return &clip, fmt.Errorf("error updating video metadata: %w", err)
+		}
+	}
+
+	return &clip, nil
+}
+
+// GetClip fetches a clip job by ID, e.g. to follow its state until the Video
+// Cloud video is created.
+func (c *Client) GetClip(ctx context.Context, accountID, clipID string) (*Clip, error) {
+	url := fmt.Sprintf("%s/v2/accounts/%s/vods/%s", c.liveURL(""), accountID, clipID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	body, err := c.doAuthorizedRequest(ctx, http.MethodGet, url, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var clip Clip
+	if err := json.Unmarshal(body, &clip); err != nil {
+		return nil, fmt.Errorf("error decoding body: %w", err)
+	}
+	if clip.ID == "" {
+		clip.ID = clipID
+	}
+
+	return &clip, nil
+}
+
+// DeleteClip deletes a clip job, cancelling it if it hasn't finished. The
+// Video Cloud video it created is kept.
+func (c *Client) DeleteClip(ctx context.Context, accountID, clipID string) error {
+	url := fmt.Sprintf("%s/v2/accounts/%s/vods/%s", c.liveURL(""), accountID, clipID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	_, err := c.doAuthorizedRequest(ctx, http.MethodDelete, url, nil, headers)
+	return err
+}
+
+// ListJobClips fetches the clip jobs already created from a live job, e.g. to
+// avoid clipping a session twice.
+func (c *Client) ListJobClips(ctx context.Context, accountID, jobID string) ([]Clip, error) {
+	url := fmt.Sprintf("%s/v2/accounts/%s/jobs/%s/vods", c.liveURL(""), accountID, jobID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	body, err := c.doAuthorizedRequest(ctx, http.MethodGet, url, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	// The clips are listed on their own or wrapped like CreateClip's
+	var clips []Clip
+	if err := json.Unmarshal(body, &clips); err != nil {
+		var resp struct {
+			VODJobs []Clip `json:"vod_jobs"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("error decoding body: %w", err)
+		}
+		clips = resp.VODJobs
+	}
+	for i := range clips {
+		if clips[i].JobID == "" {
+			clips[i].JobID = jobID
+		}
+	}
+
+	return clips, nil
+}
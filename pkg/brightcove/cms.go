@@ -0,0 +1,79 @@
+package brightcove
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Video is a Video Cloud video as known to the CMS API.
+type Video struct {
+	ID           string            `json:"id,omitempty"`
+	Name         string            `json:"name,omitempty"`
+	State        string            `json:"state,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+}
+
+// CreateVideo creates an empty Video Cloud video, e.g. to ingest media into.
+func (c *Client) CreateVideo(ctx context.Context, accountID string, video Video) (*Video, error) {
+	payload, err := json.Marshal(video)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/accounts/%s/videos", c.cmsURL(), accountID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	body, err := c.doAuthorizedRequest(ctx, http.MethodPost, url, payload, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var created Video
+	if err := json.Unmarshal(body, &created); err != nil {
+		return nil, fmt.Errorf("error decoding body: %w", err)
+	}
+
+	return &created, nil
+}
+
+// UpdateVideo writes the non-empty fields of update to an existing video.
+func (c *Client) UpdateVideo(ctx context.Context, accountID, videoID string, update Video) (*Video, error) {
+	update.ID = ""
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/accounts/%s/videos/%s", c.cmsURL(), accountID, videoID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	body, err := c.doAuthorizedRequest(ctx, http.MethodPatch, url, payload, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated Video
+	if err := json.Unmarshal(body, &updated); err != nil {
+		return nil, fmt.Errorf("error decoding body: %w", err)
+	}
+
+	return &updated, nil
+}
+
+// AddVideoToFolder moves a video into a Video Cloud folder.
+func (c *Client) AddVideoToFolder(ctx context.Context, accountID, folderID, videoID string) error {
+	url := fmt.Sprintf("%s/v1/accounts/%s/folders/%s/videos/%s", c.cmsURL(), accountID, folderID, videoID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	_, err := c.doAuthorizedRequest(ctx, http.MethodPut, url, nil, headers)
+	return err
+}
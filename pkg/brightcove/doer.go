@@ -0,0 +1,23 @@
+package brightcove
+
+import (
+	"net/http"
+)
+
+// Doer sends the HTTP requests of a Client. *http.Client implements it, other
+// implementations can instrument, record or stub the API calls.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to a Doer, e.g. to stub responses in tests
+// without a network listener:
+//
+//	client := brightcove.NewClient(brightcove.WithDoer(brightcove.DoerFunc(func(req *http.Request) (*http.Response, error) {
+//		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+//	})))
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
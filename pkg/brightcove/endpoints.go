@@ -0,0 +1,148 @@
+package brightcove
+
+import (
+	"strings"
+)
+
+// Default roots of the Brightcove APIs.
+const (
+	DefaultOAuthURL  = "https://oauth.brightcove.com"
+	DefaultLiveURL   = "https://api.live.brightcove.com"
+	DefaultCMSURL    = "https://cms.api.brightcove.com"
+	DefaultIngestURL = "https://ingest.api.brightcove.com"
+)
+
+// BaseURLs are the roots of the APIs a Client calls, e.g. to point it at a
+// staging environment or a mock server. Empty fields use the defaults.
+type BaseURLs struct {
+	// OAuth is the root of the OAuth API, DefaultOAuthURL by default.
+	OAuth string
+	// Live is the root of the Live API, DefaultLiveURL by default.
+	Live string
+	// LiveFallbacks are roots of the Live API tried in order when Live can't
+	// be reached, e.g. during a regional incident. A call failing over sends
+	// the calls of the next few minutes to the root that answered. Calls to
+	// RegionalLive don't fail over.
+	LiveFallbacks []string
+	// RegionalLive, when set, is the root of the Live API used for resources
+	// whose region is known, with {region} replaced by it, e.g.
+	// https://api.{region}.live.example.com. Calls for other resources go to
+	// Live.
+	RegionalLive string
+	// Playback is the root of the playback URL lookup, the Live API by
+	// default.
+	Playback string
+	// CMS is the root of the CMS API, DefaultCMSURL by default.
+	CMS string
+	// Ingest is the root of the Dynamic Ingest API, DefaultIngestURL by
+	// default.
+	Ingest string
+}
+
+// SetResourceRegion records the region of a live resource, e.g. ap-south-1,
+// so its Live API calls go to BaseURLs.RegionalLive. Resources without a
+// recorded region use c.Region.
+func (c *Client) SetResourceRegion(resourceID, region string) {
+	c.regionMu.Lock()
+	defer c.regionMu.Unlock()
+
+	if c.regions == nil {
+		c.regions = map[string]string{}
+	}
+	c.regions[resourceID] = region
+}
+
+// ResolvePlaybackURL is ParsePlaybackURL, also recording the region of the
+// resource the playback URL points at with SetResourceRegion.
+func (c *Client) ResolvePlaybackURL(playbackURL string) (accountID, resourceID string, err error) {
+	path, err := parsePlaybackPath(playbackURL)
+	if err != nil {
+		return "", "", err
+	}
+	c.SetResourceRegion(path.resourceID, path.region)
+	return path.accountID, path.resourceID, nil
+}
+
+// resourceRegion returns the region recorded for resourceID, or c.Region.
+func (c *Client) resourceRegion(resourceID string) string {
+	c.regionMu.Lock()
+	defer c.regionMu.Unlock()
+
+	if region, ok := c.regions[resourceID]; ok {
+		return region
+	}
+	return c.Region
+}
+
+// oauthURL returns the root of the OAuth API.
+func (c *Client) oauthURL() string {
+	return baseURL(c.BaseURLs.OAuth, DefaultOAuthURL)
+}
+
+// liveURL returns the root of the Live API for calls concerning resourceID,
+// the regional one when its region is known. An empty resourceID stands for
+// account level calls, which use c.Region.
+func (c *Client) liveURL(resourceID string) string {
+	if c.BaseURLs.RegionalLive != "" {
+		if region := c.resourceRegion(resourceID); region != "" {
+			return strings.ReplaceAll(baseURL(c.BaseURLs.RegionalLive, ""), "{region}", region)
+		}
+	}
+	return baseURL(c.BaseURLs.Live, DefaultLiveURL)
+}
+
+// playbackURL returns the root of the playback URL lookup of resourceID.
+func (c *Client) playbackURL(resourceID string) string {
+	if c.BaseURLs.Playback != "" {
+		return baseURL(c.BaseURLs.Playback, "")
+	}
+	return c.liveURL(resourceID)
+}
+
+// cmsURL returns the root of the CMS API.
+func (c *Client) cmsURL() string {
+	return baseURL(c.BaseURLs.CMS, DefaultCMSURL)
+}
+
+// ingestURL returns the root of the Dynamic Ingest API.
+func (c *Client) ingestURL() string {
+	return baseURL(c.BaseURLs.Ingest, DefaultIngestURL)
+}
+
+// defaultRoot maps the root url starts with to the default root of the same
+// API, so endpoint names reported to an Observer don't depend on BaseURLs.
+func (c *Client) defaultRoot(url string) string {
+	roots := []struct{ root, def string }{
+		{c.BaseURLs.OAuth, DefaultOAuthURL},
+		{c.BaseURLs.Playback, DefaultLiveURL},
+		{c.BaseURLs.Live, DefaultLiveURL},
+		{c.BaseURLs.CMS, DefaultCMSURL},
+		{c.BaseURLs.Ingest, DefaultIngestURL},
+	}
+	for _, root := range c.BaseURLs.LiveFallbacks {
+		roots = append(roots, struct{ root, def string }{root, DefaultLiveURL})
+	}
+	for _, r := range roots {
+		if root := baseURL(r.root, ""); root != "" && strings.HasPrefix(url, root+"/") {
+			return r.def + strings.TrimPrefix(url, root)
+		}
+	}
+
+	if c.BaseURLs.RegionalLive != "" {
+		prefix, suffix, _ := strings.Cut(baseURL(c.BaseURLs.RegionalLive, ""), "{region}")
+		if rest, ok := strings.CutPrefix(url, prefix); ok {
+			if i := strings.Index(rest, suffix+"/"); i >= 0 {
+				return DefaultLiveURL + rest[i+len(suffix):]
+			}
+		}
+	}
+	return url
+}
+
+// baseURL returns root without its trailing slashes, or def when it's empty.
+func baseURL(root, def string) string {
+	if root == "" {
+		return def
+	}
+	return strings.TrimRight(root, "/")
+}
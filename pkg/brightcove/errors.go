@@ -0,0 +1,144 @@
+package brightcove
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Errors returned by the client, check for them with errors.Is.
+var (
+	// ErrLiveSessionActive means the resource is live, which blocks VOD
+	// generation for all of its sessions.
+	ErrLiveSessionActive = errors.New("ongoing live session")
+	// ErrNoSessions means the resource has no sessions.
+	ErrNoSessions = errors.New("no sessions")
+	// ErrVODWindowExpired means every session ended before the VOD window.
+	ErrVODWindowExpired = errors.New("every session ended outside the VOD window")
+	// ErrMalformedPlaybackURL means a playback URL could not be parsed.
+	ErrMalformedPlaybackURL = errors.New("malformed playback URL provided")
+	// ErrResourceNotFound means the Live API doesn't know the resource, e.g.
+	// because the account and resource IDs of a playback URL don't match.
+	ErrResourceNotFound = errors.New("live resource not found")
+	// ErrRedundantGroup means a redundant group ID was used as a resource
+	// ID, its sessions are those of the resources of its jobs.
+	ErrRedundantGroup = errors.New("resource is a redundant group")
+	// ErrAuthentication means the OAuth API rejected the client credentials.
+	ErrAuthentication = errors.New("authentication failed")
+	// ErrResponseTooLarge means an API response exceeded
+	// Client.MaxResponseBytes.
+	ErrResponseTooLarge = errors.New("response body too large")
+)
+
+// SessionError is the failure of an API call made for a single session and
+// manifest format.
+type SessionError struct {
+	Session Session
+	Format  string
+	Err     error
+}
+
+func (e *SessionError) Error() string {
+	return fmt.Sprintf("session %s (%s): %v", e.Session.ID, e.Format, e.Err)
+}
+
+func (e *SessionError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError is returned when Client.ContinueOnError is set and some of the
+// per-session calls failed. Results of the calls that succeeded are returned
+// along with it.
+type PartialError struct {
+	Errors []*SessionError
+}
+
+func (e *PartialError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	return fmt.Sprintf("%d calls failed, first: %v", len(e.Errors), e.Errors[0])
+}
+
+func (e *PartialError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// APIError is returned for API responses with a non 200 status.
+type APIError struct {
+	Status int
+	// Code is the Brightcove error code, e.g. NOT_FOUND, and Message its
+	// description, when the body carries them.
+	Code    string
+	Message string
+	// Body is the raw response body.
+	Body string
+}
+
+// apiErrorBody is an error as reported by the APIs: error_code and message
+// for the Live, Playback and CMS APIs, error and error_description for the
+// OAuth API.
+type apiErrorBody struct {
+	ErrorCode        string `json:"error_code"`
+	Message          string `json:"message"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	err := &APIError{Status: status, Body: string(body)}
+
+	// The APIs report errors as an object or a list of objects
+	var single apiErrorBody
+	var list []apiErrorBody
+	if json.Unmarshal(body, &single) != nil {
+		if json.Unmarshal(body, &list) == nil && len(list) > 0 {
+			single = list[0]
+		}
+	}
+	err.Code = cmp.Or(single.ErrorCode, single.Error)
+	err.Message = cmp.Or(single.Message, single.ErrorDescription)
+
+	return err
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" && e.Message == "" {
+		return fmt.Sprintf("received error from API with status %d and error %s", e.Status, strings.TrimSpace(e.Body))
+	}
+
+	msg := fmt.Sprintf("API error %d", e.Status)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if hint := e.hint(); hint != "" {
+		msg += " (" + hint + ")"
+	}
+	return msg
+}
+
+// hint suggests what to do about the error, or returns "" when there is
+// nothing specific to suggest.
+func (e *APIError) hint() string {
+	switch {
+	case e.Code == "invalid_client" || e.Status == http.StatusUnauthorized:
+		return "check the client ID and secret"
+	case e.Code == "NOT_FOUND" || e.Status == http.StatusNotFound:
+		return "check the IDs in the request"
+	case e.Status == http.StatusForbidden:
+		return "the API credentials may lack the permissions, e.g. Live or CMS video read/write, check them in Studio"
+	case e.Status == http.StatusTooManyRequests:
+		return "rate limited, make fewer calls in parallel or pace them"
+	}
+	return ""
+}
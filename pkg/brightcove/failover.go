@@ -0,0 +1,66 @@
+package brightcove
+
+import (
+	"errors"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// liveFailoverPeriod is how long Live API calls keep going to the fallback
+// root they failed over to before the primary root is tried again.
+const liveFailoverPeriod = 5 * time.Minute
+
+// liveRoots returns the roots of the Live API in failover order, nil without
+// BaseURLs.LiveFallbacks.
+func (c *Client) liveRoots() []string {
+	if len(c.BaseURLs.LiveFallbacks) == 0 {
+		return nil
+	}
+	roots := []string{baseURL(c.BaseURLs.Live, DefaultLiveURL)}
+	for _, root := range c.BaseURLs.LiveFallbacks {
+		roots = append(roots, baseURL(root, ""))
+	}
+	return roots
+}
+
+// liveTarget splits a URL of the primary Live API root into the roots to try
+// in order and the rest of the URL, returning the index of the root calls
+// currently go to. roots is nil for other URLs or without fallbacks.
+func (c *Client) liveTarget(url string) (roots []string, current int, rest string) {
+	roots = c.liveRoots()
+	if roots == nil {
+		return nil, 0, url
+	}
+	rest, ok := strings.CutPrefix(url, roots[0])
+	if !ok || !strings.HasPrefix(rest, "/") {
+		return nil, 0, url
+	}
+
+	c.failoverMu.Lock()
+	defer c.failoverMu.Unlock()
+
+	if c.liveActive > 0 && time.Since(c.liveFailedOver) > liveFailoverPeriod {
+		c.liveActive = 0
+	}
+	return roots, min(c.liveActive, len(roots)-1), rest
+}
+
+// failOver makes the Live API root roots[i] the one calls go to for the next
+// liveFailoverPeriod.
+func (c *Client) failOver(i int) {
+	c.failoverMu.Lock()
+	defer c.failoverMu.Unlock()
+
+	if i > c.liveActive || time.Since(c.liveFailedOver) > liveFailoverPeriod {
+		c.liveActive = i
+		c.liveFailedOver = time.Now()
+	}
+}
+
+// unreachable reports whether an attempt failed without a response, e.g. on a
+// DNS, connection or timeout error, as opposed to an error the API returned.
+func unreachable(err error) bool {
+	var urlErr *neturl.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,121 @@
+package brightcove
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Ingest job states reported by the CMS API.
+const (
+	IngestStateProcessing = "processing"
+	IngestStatePublishing = "publishing"
+	IngestStatePublished  = "published"
+	IngestStateFinished   = "finished"
+	IngestStateFailed     = "failed"
+)
+
+// IngestRequest is a Dynamic Ingest pull request.
+type IngestRequest struct {
+	// URL is where Video Cloud pulls the media from.
+	URL string
+	// Profile is the ingest profile, the account default when empty.
+	Profile string
+}
+
+// IngestJob is the status of a Dynamic Ingest job.
+type IngestJob struct {
+	ID           string `json:"id"`
+	State        string `json:"state"`
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// Done reports whether the job stopped processing, successfully or not.
+func (j *IngestJob) Done() bool {
+	return j.State == IngestStateFinished || j.State == IngestStateFailed
+}
+
+// Ingest asks Dynamic Ingest to pull media into an existing video.
+func (c *Client) Ingest(ctx context.Context, accountID, videoID string, req IngestRequest) (*IngestJob, error) {
+	type master struct {
+		URL string `json:"url"`
+	}
+	data := struct {
+		Master        master `json:"master"`
+		Profile       string `json:"profile,omitempty"`
+		CaptureImages bool   `json:"capture-images"`
+	}{
+		Master:        master{URL: req.URL},
+		Profile:       req.Profile,
+		CaptureImages: true,
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/accounts/%s/videos/%s/ingest-requests", c.ingestURL(), accountID, videoID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	body, err := c.doAuthorizedRequest(ctx, http.MethodPost, url, payload, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var job IngestJob
+	if err := json.Unmarshal(body, &job); err != nil {
+		return nil, fmt.Errorf("error decoding body: %w", err)
+	}
+	job.State = IngestStateProcessing
+
+	return &job, nil
+}
+
+// GetIngestJob returns the current status of an ingest job.
+func (c *Client) GetIngestJob(ctx context.Context, accountID, videoID, jobID string) (*IngestJob, error) {
+	url := fmt.Sprintf("%s/v1/accounts/%s/videos/%s/ingest_jobs/%s", c.cmsURL(), accountID, videoID, jobID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	body, err := c.doAuthorizedRequest(ctx, http.MethodGet, url, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var job IngestJob
+	if err := json.Unmarshal(body, &job); err != nil {
+		return nil, fmt.Errorf("error decoding body: %w", err)
+	}
+
+	return &job, nil
+}
+
+// WaitForIngest polls an ingest job every interval until it is done or ctx
+// is. A failed job is returned along with an error.
+func (c *Client) WaitForIngest(ctx context.Context, accountID, videoID, jobID string, interval time.Duration) (*IngestJob, error) {
+	for {
+		job, err := c.GetIngestJob(ctx, accountID, videoID, jobID)
+		if err != nil {
+			return nil, err
+		}
+
+		if job.Done() {
+			if job.State == IngestStateFailed {
+				return job, fmt.Errorf("ingest job %s failed with %s: %s", job.ID, job.ErrorCode, job.ErrorMessage)
+			}
+			return job, nil
+		}
+
+		c.Logger.DebugContext(ctx, "waiting for ingest job", "job_id", jobID, "state", job.State)
+		if err := sleep(ctx, interval); err != nil {
+			return job, err
+		}
+	}
+}
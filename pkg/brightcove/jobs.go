@@ -0,0 +1,133 @@
+package brightcove
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// JobStateFinishing is the state of a live job that stopped streaming and is
+// closing its last session, whose VOD becomes available a few minutes later.
+const JobStateFinishing = "finishing"
+
+// Job is a live job as returned by the Live Jobs API.
+type Job struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	State      string   `json:"state"`
+	Labels     []string `json:"labels,omitempty"`
+	AccountID  string   `json:"account_id"`
+	ResourceID string   `json:"resource_id"`
+	// PlaybackURL is the live playback URL of the job, used to find its
+	// resource when ResourceID is not reported.
+	PlaybackURL string `json:"playback_url"`
+}
+
+// Resource returns the account and resource IDs of the job's live resource.
+func (j *Job) Resource() (accountID, resourceID string, err error) {
+	if j.ResourceID != "" && j.AccountID != "" {
+		return j.AccountID, j.ResourceID, nil
+	}
+	if j.PlaybackURL == "" {
+		return "", "", errors.New("job has no resource ID or playback URL")
+	}
+	return ParsePlaybackURL(j.PlaybackURL)
+}
+
+// JobFilter narrows down the jobs ListJobs returns. The zero value keeps
+// every job.
+type JobFilter struct {
+	// States keeps the jobs in any of these states, e.g. processing or
+	// finished, when set.
+	States []string
+	// Labels keeps the jobs carrying every one of these labels, when set.
+	Labels []string
+}
+
+// Match reports whether the job passes the filter.
+func (f JobFilter) Match(job Job) bool {
+	if len(f.States) > 0 && !slices.Contains(f.States, job.State) {
+		return false
+	}
+	for _, label := range f.Labels {
+		if !slices.Contains(job.Labels, label) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListJobs fetches the live jobs of an account matching filter, following the
+// pages of the jobs list until the last one.
+func (c *Client) ListJobs(ctx context.Context, accountID string, filter JobFilter) ([]Job, error) {
+	baseURL := fmt.Sprintf("%s/v2/accounts/%s/jobs", c.liveURL(""), accountID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	var jobs []Job
+	seen := map[string]bool{}
+	for cursor := ""; ; {
+		pageURL := baseURL
+		if cursor != "" {
+			pageURL += "?start_token=" + url.QueryEscape(cursor)
+		}
+
+		body, err := c.doAuthorizedRequest(ctx, http.MethodGet, pageURL, nil, headers)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Jobs      []Job  `json:"jobs"`
+			NextToken string `json:"next_token"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("error decoding body: %w", err)
+		}
+		for _, job := range page.Jobs {
+			if job.AccountID == "" {
+				job.AccountID = accountID
+			}
+			if filter.Match(job) {
+				jobs = append(jobs, job)
+			}
+		}
+
+		// A repeated cursor would loop forever
+		if page.NextToken == "" || seen[page.NextToken] {
+			break
+		}
+		seen[page.NextToken] = true
+		cursor = page.NextToken
+	}
+
+	return jobs, nil
+}
+
+// GetJob fetches a live job by ID.
+func (c *Client) GetJob(ctx context.Context, accountID, jobID string) (*Job, error) {
+	url := fmt.Sprintf("%s/v2/accounts/%s/jobs/%s", c.liveURL(""), accountID, jobID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	body, err := c.doAuthorizedRequest(ctx, http.MethodGet, url, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var job Job
+	if err = json.Unmarshal(body, &job); err != nil {
+		return nil, fmt.Errorf("error decoding body: %w", err)
+	}
+	if job.AccountID == "" {
+		job.AccountID = accountID
+	}
+
+	return &job, nil
+}
@@ -0,0 +1,54 @@
+package brightcove
+
+import (
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Observer is notified of the client's API activity, e.g. to export metrics.
+// Its methods may be called concurrently.
+type Observer interface {
+	// ObserveRequest is called after every attempt of an API call with the
+	// endpoint, the response status (0 if no response was received) and how
+	// long the attempt took.
+	ObserveRequest(endpoint string, status int, duration time.Duration)
+	// ObserveRetry is called before a failed API call is retried.
+	ObserveRetry(endpoint string)
+}
+
+// Endpoint names used by the client, as reported to an Observer.
+const (
+	EndpointAccessToken   = "POST oauth.brightcove.com/v4/access_token"
+	EndpointPlaybackToken = "POST api.live.brightcove.com/v2/accounts/{id}/playback/{id}/token"
+	EndpointPlaybackURL   = "GET api.live.brightcove.com/v2/playback/{id}"
+)
+
+// endpointName turns a request into a low cardinality endpoint name by
+// replacing the IDs in its path with {id} and dropping the query, e.g.
+// "GET api.live.brightcove.com/v2/accounts/{id}/sessions/resource/{id}".
+func endpointName(method, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return method
+	}
+
+	parts := strings.Split(u.Path, "/")
+	for i, part := range parts {
+		if isPathID(part) {
+			parts[i] = "{id}"
+		}
+	}
+
+	return method + " " + u.Host + strings.Join(parts, "/")
+}
+
+// isPathID reports whether a path segment looks like an ID rather than a
+// fixed part of the API, i.e. it holds a digit and is not a version like v2.
+func isPathID(part string) bool {
+	if len(part) >= 2 && part[0] == 'v' && strings.IndexFunc(part[1:], func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+		return false
+	}
+	return strings.IndexFunc(part, unicode.IsDigit) != -1
+}
@@ -0,0 +1,121 @@
+package brightcove
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// defaultTimeout bounds every HTTP request of a Client given no HTTP client
+// or timeout.
+const defaultTimeout = 10 * time.Second
+
+// Option configures a Client built by NewClient.
+type Option func(*Client)
+
+// WithCredentials sets the OAuth client credentials.
+func WithCredentials(clientID, clientSecret string) Option {
+	return func(c *Client) {
+		c.clientID, c.clientSecret = clientID, clientSecret
+	}
+}
+
+// WithCredentialsProvider fetches the client credentials from p every time a
+// new access token is requested, see Client.Credentials.
+func WithCredentialsProvider(p CredentialsProvider) Option {
+	return func(c *Client) {
+		c.Credentials = p
+	}
+}
+
+// WithHTTPClient sends the API calls through httpClient, e.g. to go through a
+// proxy or an instrumented transport.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return WithDoer(httpClient)
+}
+
+// WithDoer sends the API calls through d instead of an HTTP client.
+func WithDoer(d Doer) Option {
+	return func(c *Client) {
+		c.doer = d
+	}
+}
+
+// WithTimeout bounds every HTTP request, 10 seconds by default. It applies
+// to a client given with WithHTTPClient too, without modifying it, but not
+// to other Doers.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithBaseURLs overrides the roots of the APIs, see BaseURLs.
+func WithBaseURLs(urls BaseURLs) Option {
+	return func(c *Client) {
+		c.BaseURLs = urls
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every API call.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.UserAgent = userAgent
+	}
+}
+
+// WithRetryPolicy sets the retry policy of every API call,
+// DefaultRetryPolicy by default.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.Retry = policy
+	}
+}
+
+// WithAdConfigID requests the VOD playback URLs with a server-side ad
+// insertion configuration, see Client.AdConfigID.
+func WithAdConfigID(adConfigID string) Option {
+	return func(c *Client) {
+		c.AdConfigID = adConfigID
+	}
+}
+
+// WithPlaybackAuthorizer authorizes the VOD playback URLs with the JWTs of
+// a, see Client.PlaybackAuth.
+func WithPlaybackAuthorizer(a PlaybackAuthorizer) Option {
+	return func(c *Client) {
+		c.PlaybackAuth = a
+	}
+}
+
+// WithURLParams appends params to every VOD playback URL, see
+// Client.URLParams.
+func WithURLParams(params map[string]string) Option {
+	return func(c *Client) {
+		c.URLParams = params
+	}
+}
+
+// WithMaxResponseBytes caps the size of API response bodies, see
+// Client.MaxResponseBytes.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.MaxResponseBytes = n
+	}
+}
+
+// WithLogger sets the logger of the client's diagnostics, slog.Default() by
+// default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.Logger = logger
+	}
+}
+
+// WithSessionCache makes GetResourceSessions reuse the sessions of a resource
+// fetched within ttl, see SessionCache.
+func WithSessionCache(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.SessionCache = NewSessionCache(ttl)
+	}
+}
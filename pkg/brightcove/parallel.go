@@ -0,0 +1,99 @@
+package brightcove
+
+import (
+	"context"
+	"sync"
+)
+
+// parallel calls fn for every index in [0, n) with at most limit calls in
+// flight. It stops starting new calls after the first failure and returns
+// that failure. A limit below 2 runs the calls one after the other.
+func parallel(ctx context.Context, limit, n int, fn func(ctx context.Context, i int) error) error {
+	if limit < 2 {
+		for i := range n {
+			if err := fn(ctx, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	slots := make(chan struct{}, limit)
+
+	for i := range n {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// each runs fn for every index in [0, n) as configured by Concurrency. With
+// ContinueOnError set every call is made and their errors are returned by
+// index, otherwise the first failure stops the run and is returned as err.
+func (c *Client) each(ctx context.Context, n int, fn func(ctx context.Context, i int) error) (errs []error, err error) {
+	if !c.ContinueOnError {
+		return nil, parallel(ctx, c.Concurrency, n, fn)
+	}
+
+	errs = make([]error, n)
+	err = parallel(ctx, c.Concurrency, n, func(ctx context.Context, i int) error {
+		errs[i] = fn(ctx, i)
+		return nil
+	})
+	return errs, err
+}
+
+// succeeded drops the items whose call failed according to errs, as returned
+// by each. The failures are reported as a *PartialError along with the items
+// that succeeded, or on their own if every call failed.
+func succeeded[T any](items []T, errs []error, describe func(T) (Session, string)) ([]T, error) {
+	var ok []T
+	var partial PartialError
+	for i, item := range items {
+		if errs == nil || errs[i] == nil {
+			ok = append(ok, item)
+			continue
+		}
+		session, format := describe(item)
+		partial.Errors = append(partial.Errors, &SessionError{Session: session, Format: format, Err: errs[i]})
+	}
+
+	if len(partial.Errors) == 0 {
+		return ok, nil
+	}
+	if len(ok) == 0 {
+		return nil, &partial
+	}
+	return ok, &partial
+}
@@ -0,0 +1,261 @@
+package brightcove
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	neturl "net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PlaybackToken authorizes VOD playback of a single session in one manifest format.
+type PlaybackToken struct {
+	Token   string  `json:"token"`
+	Session Session `json:"-"`
+	Format  string  `json:"-"`
+	// ExpiresAt is when the token stops granting playback: the end of the
+	// session's VOD window, or earlier when c.PlaybackTokenTTL runs out first.
+	ExpiresAt time.Time `json:"-"`
+}
+
+// PlaybackURL is a VOD playback URL for a single session in one manifest format.
+type PlaybackURL struct {
+	URL     string  `json:"url"`
+	Token   string  `json:"-"`
+	Session Session `json:"-"`
+	Format  string  `json:"-"`
+	// ExpiresAt is the expiry of the URL's playback token.
+	ExpiresAt time.Time `json:"-"`
+}
+
+// GeneratePlaybackTokens requests a playback token per manifest format
+// (ManifestFormatHLS and/or ManifestFormatDASH) for every session that ended
+// within the VOD window. Tokens are returned grouped by session, in the order
+// the formats were given. HLS is used when no format is given. Up to
+// c.Concurrency tokens are requested in parallel. A live session makes it
+// fail with ErrLiveSessionActive unless c.SkipLive is set. With c.ContinueOnError set,
+// the tokens that could be created are returned along with a *PartialError.
+func (c *Client) GeneratePlaybackTokens(ctx context.Context, sessions *Sessions, formats ...string) ([]PlaybackToken, error) {
+	var playbackTokens []PlaybackToken
+
+	if len(formats) == 0 {
+		formats = []string{ManifestFormatHLS}
+	}
+	for _, format := range formats {
+		if format != ManifestFormatHLS && format != ManifestFormatDASH {
+			return nil, fmt.Errorf("unsupported manifest format %q", format)
+		}
+	}
+
+	if len(sessions.Events) == 0 {
+		return nil, fmt.Errorf("%w, quitting", ErrNoSessions)
+	}
+	if !c.SkipLive {
+		if err := sessions.CheckNotLive(); err != nil {
+			return nil, err
+		}
+	}
+
+	session := sessions.Events[0]
+
+	url := fmt.Sprintf("%s/v2/accounts/%s/playback/%s/token", c.liveURL(session.ResourceID), session.AccountID, session.ResourceID)
+
+	for _, session := range sessions.Events {
+		if session.EndTime == 0 {
+			c.Logger.InfoContext(ctx, "skipping live session", "session_id", session.ID)
+			continue
+		}
+		// Skip generating a token for sessions that ended before the VOD window
+		if !session.WithinVODWindow(c.VODWindowDays) {
+			c.Logger.InfoContext(ctx, "skipping session outside the VOD window", "session_id", session.ID, "end_time", session.EndTime, "vod_window_days", c.VODWindowDays)
+			continue
+		}
+		for _, format := range formats {
+			playbackTokens = append(playbackTokens, PlaybackToken{Session: session, Format: format})
+		}
+	}
+
+	if len(playbackTokens) == 0 {
+		return nil, fmt.Errorf("no valid sessions to continue, %w of %d days", ErrVODWindowExpired, c.VODWindowDays)
+	}
+
+	errs, err := c.each(ctx, len(playbackTokens), func(ctx context.Context, i int) error {
+		issuedAt := time.Now()
+		token, err := c.generatePlaybackToken(ctx, url, playbackTokens[i].Session, playbackTokens[i].Format)
+		if err != nil {
+			return err
+		}
+		playbackTokens[i].Token = token
+		playbackTokens[i].ExpiresAt = c.playbackTokenExpiry(playbackTokens[i].Session, issuedAt)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return succeeded(playbackTokens, errs, func(t PlaybackToken) (Session, string) { return t.Session, t.Format })
+}
+
+// playbackTokenExpiry computes when a token of session issued at issuedAt
+// expires.
+func (c *Client) playbackTokenExpiry(session Session, issuedAt time.Time) time.Time {
+	expiry := session.VODExpiry(c.VODWindowDays)
+	if c.PlaybackTokenTTL > 0 {
+		if ttlExpiry := issuedAt.Add(c.PlaybackTokenTTL).UTC(); ttlExpiry.Before(expiry) {
+			return ttlExpiry
+		}
+	}
+	return expiry
+}
+
+// generatePlaybackToken requests a single playback token for session.
+func (c *Client) generatePlaybackToken(ctx context.Context, url string, session Session, format string) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "brightcove.generatePlaybackToken", sessionAttributes(session, format))
+	defer func() { endSpan(span, err) }()
+
+	data := struct {
+		StartTime      string `json:"start_time"`
+		EndTime        string `json:"end_time"`
+		ManifestFormat string `json:"manifest_format"`
+		TTL            int    `json:"ttl,omitempty"`
+	}{
+		StartTime:      strconv.Itoa(session.StartTime),
+		EndTime:        strconv.Itoa(session.EndTime),
+		ManifestFormat: format,
+		TTL:            int(c.PlaybackTokenTTL.Seconds()),
+	}
+	var buf bytes.Buffer
+	err = json.NewEncoder(&buf).Encode(data)
+	if err != nil {
+		return "", fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	body, err := c.doAuthorizedRequest(ctx, http.MethodPost, url, buf.Bytes(), headers)
+	if err != nil {
+		return "", err
+	}
+
+	var playbackToken PlaybackToken
+	err = json.Unmarshal(body, &playbackToken)
+	if err != nil {
+		return "", fmt.Errorf("error decoding body: %w", err)
+	}
+
+	return playbackToken.Token, nil
+}
+
+// GeneratePlaybackURLs resolves each playback token into a VOD playback URL.
+func (c *Client) GeneratePlaybackURLs(ctx context.Context, tokens []PlaybackToken, resourceID string) ([]PlaybackURL, error) {
+	playbackURLs := make([]PlaybackURL, len(tokens))
+
+	errs, err := c.each(ctx, len(tokens), func(ctx context.Context, i int) error {
+		playbackURL, err := c.generatePlaybackURL(ctx, tokens[i], resourceID)
+		if err != nil {
+			// Kept so the failure tells its session
+			playbackURLs[i].Session, playbackURLs[i].Format = tokens[i].Session, tokens[i].Format
+			return err
+		}
+		playbackURLs[i] = *playbackURL
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return succeeded(playbackURLs, errs, func(u PlaybackURL) (Session, string) { return u.Session, u.Format })
+}
+
+// generatePlaybackURL resolves a single playback token into a VOD playback URL.
+func (c *Client) generatePlaybackURL(ctx context.Context, token PlaybackToken, resourceID string) (_ *PlaybackURL, err error) {
+	ctx, span := tracer.Start(ctx, "brightcove.generatePlaybackURL", sessionAttributes(token.Session, token.Format))
+	defer func() { endSpan(span, err) }()
+
+	url := fmt.Sprintf("%s/v2/playback/%s?pt=%s", c.playbackURL(resourceID), resourceID, token.Token)
+	if c.AdConfigID != "" {
+		url += "&ad_config_id=" + neturl.QueryEscape(c.AdConfigID)
+	}
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+	var jwt string
+	if c.PlaybackAuth != nil {
+		if jwt, err = c.PlaybackAuth.PlaybackJWT(ctx, token.Session); err != nil {
+			return nil, fmt.Errorf("error getting playback authorization JWT: %w", err)
+		}
+		headers.Set("Authorization", "Bearer "+jwt)
+	}
+
+	body, err := c.doRequest(ctx, http.MethodGet, url, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var playbackURL PlaybackURL
+	err = json.Unmarshal(body, &playbackURL)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding body: %w", err)
+	}
+
+	if c.AdConfigID != "" {
+		if playbackURL.URL, err = withQueryParam(playbackURL.URL, "ad_config_id", c.AdConfigID); err != nil {
+			return nil, err
+		}
+	}
+	if jwt != "" {
+		if playbackURL.URL, err = withQueryParam(playbackURL.URL, "bcov_auth", jwt); err != nil {
+			return nil, err
+		}
+	}
+	// Sorted so the URLs of every session read the same
+	for _, key := range slices.Sorted(maps.Keys(c.URLParams)) {
+		if playbackURL.URL, err = withQueryParam(playbackURL.URL, key, c.URLParams[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	playbackURL.Token = token.Token
+	playbackURL.Session = token.Session
+	playbackURL.Format = token.Format
+	playbackURL.ExpiresAt = token.ExpiresAt
+	return &playbackURL, nil
+}
+
+// withQueryParam appends the query parameter key to rawURL, unless it is set
+// already. The existing query is kept as is, as it may be signed.
+func withQueryParam(rawURL, key, value string) (string, error) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("error parsing playback URL: %w", err)
+	}
+	if u.Query().Has(key) {
+		return rawURL, nil
+	}
+	param := neturl.QueryEscape(key) + "=" + neturl.QueryEscape(value)
+	if u.RawQuery != "" {
+		param = "&" + param
+	}
+	u.RawQuery += param
+	return u.String(), nil
+}
+
+// sessionAttributes are the span attributes of an operation on one session.
+func sessionAttributes(session Session, format string) trace.SpanStartEventOption {
+	return trace.WithAttributes(
+		attribute.String("account_id", session.AccountID),
+		attribute.String("resource_id", session.ResourceID),
+		attribute.String("session_id", session.ID),
+		attribute.String("format", format),
+	)
+}
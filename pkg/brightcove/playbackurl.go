@@ -0,0 +1,121 @@
+package brightcove
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// regionPattern matches the region segment of a playback URL, e.g.
+	// ap-south-1 or us-gov-west-1.
+	regionPattern = regexp.MustCompile(`^[a-z]{2}(-gov)?-[a-z]+-[0-9]+$`)
+	// accountIDPattern matches a Video Cloud account ID.
+	accountIDPattern = regexp.MustCompile(`^[0-9]+$`)
+	// resourceIDPattern matches a live resource ID, numeric or hex.
+	resourceIDPattern = regexp.MustCompile(`^[0-9A-Za-z]+$`)
+	// redundantGroupIDPattern matches a redundant group ID, which takes the
+	// place of the resource ID in the playback URLs of redundant groups.
+	redundantGroupIDPattern = regexp.MustCompile(`^rg-[0-9A-Za-z]+$`)
+)
+
+// playbackPath is what the path of a NextGenLive playback URL tells about its
+// resource.
+type playbackPath struct {
+	accountID  string
+	resourceID string
+	region     string
+}
+
+// parsePlaybackPath finds the <resource ID>/<region>/<account ID> segments of
+// a NextGenLive playback URL. They are located by the region rather than by
+// position, so the URL shapes served by the different CDN hostnames all parse:
+//
+//	https://fastly.live.brightcove.com/<resource>/<region>/<account>/<token>/playlist-hls.m3u8
+//	https://bcovlive-a.akamaihd.net/<resource>/<region>/<account>/playlist.m3u8
+//	https://d2xxxxxxxx.cloudfront.net/<prefix>/<resource>/<region>/<account>/<token>/playlist_dvr.m3u8
+//
+// The resource of a redundant group's playback URL is the group ID, e.g.
+// rg-5d2f..., see IsRedundantGroupID.
+//
+// The query, e.g. a playback token, and anything after the account ID, e.g.
+// DVR or rendition playlists, are ignored. A URL without a scheme is taken as
+// https.
+func parsePlaybackPath(playbackURL string) (playbackPath, error) {
+	playbackURL = strings.TrimSpace(playbackURL)
+	if !strings.Contains(playbackURL, "://") {
+		playbackURL = "https://" + playbackURL
+	}
+
+	parsedURL, err := url.Parse(playbackURL)
+	if err != nil {
+		return playbackPath{}, fmt.Errorf("%w: %w", ErrMalformedPlaybackURL, err)
+	}
+	if parsedURL.Host == "" {
+		return playbackPath{}, fmt.Errorf("%w: %q has no host", ErrMalformedPlaybackURL, playbackURL)
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(parsedURL.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	for i := 1; i < len(segments)-1; i++ {
+		if !regionPattern.MatchString(segments[i]) {
+			continue
+		}
+		path := playbackPath{resourceID: segments[i-1], region: segments[i], accountID: segments[i+1]}
+		if !resourceIDPattern.MatchString(path.resourceID) && !IsRedundantGroupID(path.resourceID) {
+			return playbackPath{}, fmt.Errorf("%w: invalid resource ID %q before region %s", ErrMalformedPlaybackURL, path.resourceID, path.region)
+		}
+		if !accountIDPattern.MatchString(path.accountID) {
+			return playbackPath{}, fmt.Errorf("%w: invalid account ID %q after region %s, expected a number", ErrMalformedPlaybackURL, path.accountID, path.region)
+		}
+		return path, nil
+	}
+
+	return playbackPath{}, fmt.Errorf("%w: expected the path to hold <resource ID>/<region>/<account ID>, e.g. /6384185469112/ap-south-1/6415518627001/.../playlist-hls.m3u8, got %s", ErrMalformedPlaybackURL, parsedURL.Path)
+}
+
+// ParsePlaybackURL extracts the account and resource IDs from a NextGenLive
+// playback URL, of any of the CDN hostnames Brightcove serves live streams
+// from.
+func ParsePlaybackURL(playbackURL string) (accountID, resourceID string, err error) {
+	path, err := parsePlaybackPath(playbackURL)
+	if err != nil {
+		return "", "", err
+	}
+	return path.accountID, path.resourceID, nil
+}
+
+// PlaybackURLRegion returns the region segment of a NextGenLive playback URL,
+// e.g. ap-south-1, or "" if it can't be parsed.
+func PlaybackURLRegion(playbackURL string) string {
+	path, err := parsePlaybackPath(playbackURL)
+	if err != nil {
+		return ""
+	}
+	return path.region
+}
+
+// PlaybackURLFormat returns the manifest format of a playback URL by the
+// extension of its path: ManifestFormatDASH for .mpd, ManifestFormatHLS for
+// .m3u8, or "" for anything else.
+func PlaybackURLFormat(playbackURL string) string {
+	parsedURL, err := url.Parse(strings.TrimSpace(playbackURL))
+	if err != nil {
+		return ""
+	}
+
+	switch strings.ToLower(path.Ext(parsedURL.Path)) {
+	case ".mpd":
+		return ManifestFormatDASH
+	case ".m3u8":
+		return ManifestFormatHLS
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package brightcove
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		rps      float64
+		burst    int
+		calls    int
+		min, max time.Duration
+	}{
+		{name: "within the burst", rps: 1, burst: 3, calls: 3, max: 100 * time.Millisecond},
+		{name: "paced", rps: 100, burst: 1, calls: 4, min: 25 * time.Millisecond, max: time.Second},
+		{name: "burst below 1", rps: 100, burst: 0, calls: 2, min: 5 * time.Millisecond, max: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tt.rps, tt.burst)
+
+			start := time.Now()
+			for range tt.calls {
+				if err := limiter.Wait(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if elapsed := time.Since(start); elapsed < tt.min || elapsed > tt.max {
+				t.Errorf("%d calls took %s, want between %s and %s", tt.calls, elapsed, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRateLimiterCanceled(t *testing.T) {
+	limiter := NewRateLimiter(0.01, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	for _, rps := range []float64{0, -1, math.NaN()} {
+		limiter := NewRateLimiter(rps, 1)
+		if limiter != nil {
+			t.Fatalf("got a limiter for %v requests per second, want nil", rps)
+		}
+		for range 3 {
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package brightcove
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveKeys are log attribute keys whose values are never logged.
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"client_secret": true,
+	"access_token":  true,
+	"token":         true,
+	"pt":            true,
+	"bcov_auth":     true,
+}
+
+// RedactAttr is a slog.HandlerOptions.ReplaceAttr function hiding the values
+// of attributes that hold credentials or tokens, in case callers log them.
+func RedactAttr(groups []string, a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+// redactURL hides the playback token and authorization JWT carried in the pt
+// and bcov_auth query parameters.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	query := u.Query()
+	if !query.Has("pt") && !query.Has("bcov_auth") {
+		return rawURL
+	}
+	for _, key := range []string{"pt", "bcov_auth"} {
+		if query.Has(key) {
+			query.Set(key, redacted)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// redactHeaders returns a copy of headers with credentials hidden.
+func redactHeaders(headers http.Header) http.Header {
+	headers = headers.Clone()
+	if headers.Get("Authorization") != "" {
+		headers.Set("Authorization", redacted)
+	}
+	return headers
+}
@@ -0,0 +1,55 @@
+package brightcove
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// RedundantGroup is a Live redundant group as returned by the Live API: live
+// jobs, its legs, streaming the same content behind a single playback URL.
+type RedundantGroup struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	AccountID string `json:"account_id"`
+	// Jobs are the legs of the group. The API may only report their IDs,
+	// fetch them with GetJob to find their resources.
+	Jobs []Job `json:"jobs"`
+}
+
+// IsRedundantGroupID reports whether id, taken from the resource segment of a
+// playback URL, is the ID of a redundant group rather than of a live resource.
+// Sessions belong to the resources of the group's jobs, not to the group.
+func IsRedundantGroupID(id string) bool {
+	return redundantGroupIDPattern.MatchString(id)
+}
+
+// GetRedundantGroup fetches a redundant group by ID.
+func (c *Client) GetRedundantGroup(ctx context.Context, accountID, groupID string) (*RedundantGroup, error) {
+	url := fmt.Sprintf("%s/v2/accounts/%s/redundantgroups/%s", c.liveURL(""), accountID, groupID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	body, err := c.doAuthorizedRequest(ctx, http.MethodGet, url, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var group RedundantGroup
+	if err = json.Unmarshal(body, &group); err != nil {
+		return nil, fmt.Errorf("error decoding body: %w", err)
+	}
+	if group.AccountID == "" {
+		group.AccountID = accountID
+	}
+	for i := range group.Jobs {
+		if group.Jobs[i].AccountID == "" {
+			group.Jobs[i].AccountID = accountID
+		}
+	}
+
+	return &group, nil
+}
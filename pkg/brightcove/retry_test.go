@@ -0,0 +1,124 @@
+package brightcove
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter time.Duration
+		min, max   time.Duration
+	}{
+		{name: "first retry", attempt: 1, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "doubled", attempt: 3, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{name: "capped", attempt: 10, min: 500 * time.Millisecond, max: time.Second},
+		{name: "overflowing", attempt: 100, min: 500 * time.Millisecond, max: time.Second},
+		{name: "retry after", attempt: 1, retryAfter: 700 * time.Millisecond, min: 700 * time.Millisecond, max: 700 * time.Millisecond},
+		{name: "retry after capped", attempt: 1, retryAfter: time.Minute, min: time.Second, max: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Jitter makes every delay different, they must all be in range
+			for range 50 {
+				if got := policy.backoff(tt.attempt, tt.retryAfter); got < tt.min || got > tt.max {
+					t.Fatalf("got %s, want between %s and %s", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		min, max time.Duration
+	}{
+		{value: ""},
+		{value: "3", min: 3 * time.Second, max: 3 * time.Second},
+		{value: "0"},
+		{value: "-1"},
+		{value: "soon"},
+		{value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), min: 58 * time.Second, max: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value); got < tt.min || got > tt.max {
+				t.Errorf("got %s, want between %s and %s", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestDoRequestRetries(t *testing.T) {
+	tests := []struct {
+		name string
+		// statuses are those of the first responses, the following ones
+		// are 200s.
+		statuses  []int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "success", wantCalls: 1},
+		{name: "rate limited", statuses: []int{http.StatusTooManyRequests}, wantCalls: 2},
+		{name: "server errors", statuses: []int{http.StatusInternalServerError, http.StatusServiceUnavailable}, wantCalls: 3},
+		{name: "attempts exhausted", statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, wantCalls: 3, wantErr: true},
+		{name: "client error", statuses: []int{http.StatusBadRequest}, wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= len(tt.statuses) {
+					w.WriteHeader(tt.statuses[calls-1])
+					return
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer srv.Close()
+			c := newTestClient(srv)
+
+			body, err := c.doRequest(context.Background(), http.MethodGet, srv.URL, nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if err == nil && string(body) != "ok" {
+				t.Errorf("got body %q", body)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// newTestClient returns a client sending every API call to srv, whatever its
+// host, and retrying like the default policy without waiting.
+func newTestClient(srv *httptest.Server) *Client {
+	target, _ := url.Parse(srv.URL)
+	return NewClient(
+		WithCredentials("test-client-id", "test-client-secret"),
+		WithHTTPClient(&http.Client{Transport: rewriteTransport{target: target, base: srv.Client().Transport}}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	)
+}
+
+type rewriteTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return t.base.RoundTrip(req)
+}
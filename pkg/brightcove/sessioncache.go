@@ -0,0 +1,79 @@
+package brightcove
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// SessionCache keeps the sessions lists fetched by GetResourceSessions for a
+// while, so repeated lookups of the same resource, e.g. by a long-running
+// server, don't call the API every time. Lists holding a live session aren't
+// cached, as they change once the stream ends. It is safe for concurrent use.
+type SessionCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]sessionCacheEntry
+}
+
+type sessionCacheEntry struct {
+	sessions  Sessions
+	expiresAt time.Time
+}
+
+// NewSessionCache returns a SessionCache keeping sessions lists for ttl.
+func NewSessionCache(ttl time.Duration) *SessionCache {
+	return &SessionCache{ttl: ttl, entries: map[string]sessionCacheEntry{}}
+}
+
+// get returns a copy of the cached sessions of a resource, if they haven't
+// expired yet.
+func (c *SessionCache) get(accountID, resourceID string) (*Sessions, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := accountID + "/" + resourceID
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	sessions := entry.sessions
+	sessions.Events = slices.Clone(sessions.Events)
+	return &sessions, true
+}
+
+// put caches a copy of the sessions of a resource, unless one is live.
+func (c *SessionCache) put(accountID, resourceID string, sessions *Sessions) {
+	if sessions.CheckNotLive() != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop expired entries so resources looked up once don't pile up
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	entry := sessionCacheEntry{sessions: *sessions, expiresAt: now.Add(c.ttl)}
+	entry.sessions.Events = slices.Clone(sessions.Events)
+	c.entries[accountID+"/"+resourceID] = entry
+}
+
+// Invalidate drops the cached sessions of a resource, e.g. after starting a
+// new session of it.
+func (c *SessionCache) Invalidate(accountID, resourceID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, accountID+"/"+resourceID)
+}
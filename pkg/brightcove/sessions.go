@@ -0,0 +1,302 @@
+package brightcove
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sessions is the response of the Live API sessions endpoint.
+type Sessions struct {
+	Events []Session `json:"sessions"`
+	// NextToken is the cursor of the next page, empty on the last one.
+	NextToken string `json:"next_token,omitempty"`
+}
+
+// Session is a single broadcast of a live resource.
+type Session struct {
+	ID         string `json:"id"`
+	ResourceID string `json:"resource_id"`
+	AccountID  string `json:"account_id"`
+	StartTime  int    `json:"start_time"`
+	EndTime    int    `json:"end_time"`
+	// JobID is the live job that streamed the session, when reported.
+	JobID string `json:"job_id,omitempty"`
+	// BroadcastEndTime, when set, is when the session actually ended, for a
+	// part of it whose EndTime is earlier, e.g. a chunk or a session trimmed
+	// at its end. Brightcove keeps
+	// the VOD available from the end of the broadcast, so the VOD window
+	// runs from it.
+	BroadcastEndTime int `json:"-"`
+}
+
+// CheckNotLive returns an error if any session is currently live (EndTime ==
+// 0). When a resource is live, the API won't allow VOD generation for ANY
+// sessions, so callers narrowing down sessions should check the full list.
+func (s *Sessions) CheckNotLive() error {
+	if session := s.Live(); session != nil {
+		return fmt.Errorf("resource %s has an %w, cannot generate VOD URLs until the stream ends", session.ResourceID, ErrLiveSessionActive)
+	}
+	return nil
+}
+
+// Live returns the session that is currently live, nil if none is.
+func (s *Sessions) Live() *Session {
+	for i, session := range s.Events {
+		if session.EndTime == 0 {
+			return &s.Events[i]
+		}
+	}
+	return nil
+}
+
+// WithinVODWindow reports whether the session ended within the last days days,
+// i.e. VOD URLs can still be generated for it.
+func (s Session) WithinVODWindow(days int) bool {
+	return !time.Unix(int64(s.broadcastEnd()), 0).Before(time.Now().UTC().AddDate(0, 0, -days))
+}
+
+// VODExpiry returns when VOD URLs of the session stop being available, days
+// days after it ended.
+func (s Session) VODExpiry(days int) time.Time {
+	return time.Unix(int64(s.broadcastEnd()), 0).UTC().AddDate(0, 0, days)
+}
+
+// broadcastEnd returns when the session ended, BroadcastEndTime if set.
+func (s Session) broadcastEnd() int {
+	return cmp.Or(s.BroadcastEndTime, s.EndTime)
+}
+
+// GetSessions fetches every session of the resource the playback URL points at
+// and returns them along with the resource ID.
+func (c *Client) GetSessions(ctx context.Context, playbackURL string) (*Sessions, string, error) {
+	accountID, resourceID, err := c.ResolvePlaybackURL(playbackURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sessions, err := c.GetResourceSessions(ctx, accountID, resourceID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return sessions, resourceID, nil
+}
+
+// SessionFilter narrows down the sessions ListResourceSessions collects. The
+// zero value keeps every session.
+type SessionFilter struct {
+	// StartedAfter and StartedBefore keep the sessions started at or after
+	// and before these times, when set.
+	StartedAfter  time.Time
+	StartedBefore time.Time
+	// Max stops listing once this many sessions matched, when above 0.
+	Max int
+}
+
+// Match reports whether the session started within the filter's date range.
+func (f SessionFilter) Match(s Session) bool {
+	started := time.Unix(int64(s.StartTime), 0)
+	if !f.StartedAfter.IsZero() && started.Before(f.StartedAfter) {
+		return false
+	}
+	if !f.StartedBefore.IsZero() && !started.Before(f.StartedBefore) {
+		return false
+	}
+	return true
+}
+
+// GetResourceSessions fetches every session of a resource, following the
+// pages of the sessions list until the last one or until c.MaxSessions
+// sessions were collected. Sessions are returned oldest first. With
+// c.SessionCache set, a recently fetched list is reused instead.
+func (c *Client) GetResourceSessions(ctx context.Context, accountID, resourceID string) (_ *Sessions, err error) {
+	ctx, span := tracer.Start(ctx, "brightcove.GetResourceSessions", trace.WithAttributes(
+		attribute.String("account_id", accountID),
+		attribute.String("resource_id", resourceID),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if c.SessionCache != nil {
+		if sessions, ok := c.SessionCache.get(accountID, resourceID); ok {
+			c.Logger.DebugContext(ctx, "using cached sessions", "resource_id", resourceID, "sessions", len(sessions.Events))
+			span.SetAttributes(attribute.Bool("cached", true), attribute.Int("sessions", len(sessions.Events)))
+			return sessions, nil
+		}
+	}
+
+	sessions, err := c.listSessions(ctx, accountID, resourceID, SessionFilter{Max: c.MaxSessions})
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("sessions", len(sessions.Events)))
+
+	if c.SessionCache != nil {
+		c.SessionCache.put(accountID, resourceID, sessions)
+	}
+	return sessions, nil
+}
+
+// ListResourceSessions fetches the sessions of a resource matching filter,
+// oldest first. Sessions are filtered while the pages of the sessions list
+// are decoded, so only the matching ones are held in memory, and listing
+// stops as soon as filter.Max sessions matched. c.SessionCache is neither
+// used nor filled.
+//
+// A list narrowed down by date can leave out a live session, pass the
+// complete list to Sessions.CheckNotLive.
+func (c *Client) ListResourceSessions(ctx context.Context, accountID, resourceID string, filter SessionFilter) (_ *Sessions, err error) {
+	ctx, span := tracer.Start(ctx, "brightcove.ListResourceSessions", trace.WithAttributes(
+		attribute.String("account_id", accountID),
+		attribute.String("resource_id", resourceID),
+	))
+	defer func() { endSpan(span, err) }()
+
+	sessions, err := c.listSessions(ctx, accountID, resourceID, filter)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("sessions", len(sessions.Events)))
+	return sessions, nil
+}
+
+// listSessions follows the pages of the sessions list of a resource,
+// collecting the sessions matching filter.
+func (c *Client) listSessions(ctx context.Context, accountID, resourceID string, filter SessionFilter) (*Sessions, error) {
+	// The sessions list of a group is a 404 that would blame the IDs
+	if IsRedundantGroupID(resourceID) {
+		return nil, fmt.Errorf("%w: %s, list the sessions of the resources of its jobs", ErrRedundantGroup, resourceID)
+	}
+	baseURL := fmt.Sprintf("%s/v2/accounts/%s/sessions/resource/%s", c.liveURL(resourceID), accountID, resourceID)
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+	}
+
+	var sessions Sessions
+	seen := map[string]bool{}
+	for cursor := ""; ; {
+		pageURL := baseURL
+		if cursor != "" {
+			pageURL += "?start_token=" + url.QueryEscape(cursor)
+		}
+
+		var nextToken string
+		decode := func(body io.Reader) error {
+			var err error
+			page := sessions.Events
+			sessions.Events, nextToken, err = decodeSessionsPage(body, filter, page)
+			if err != nil {
+				// Start over from the previous pages on a retry
+				sessions.Events = page
+			}
+			return err
+		}
+		err := c.doAuthorizedDecode(ctx, http.MethodGet, pageURL, nil, headers, decode)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: resource %s of account %s, check the IDs or the playback URL they were taken from: %w", ErrResourceNotFound, resourceID, accountID, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if filter.Max > 0 && len(sessions.Events) >= filter.Max {
+			break
+		}
+		// A repeated cursor would loop forever
+		if nextToken == "" || seen[nextToken] {
+			break
+		}
+		seen[nextToken] = true
+		cursor = nextToken
+		c.Logger.DebugContext(ctx, "fetching next page of sessions", "resource_id", resourceID, "sessions", len(sessions.Events))
+	}
+
+	slices.SortStableFunc(sessions.Events, func(a, b Session) int { return cmp.Compare(a.StartTime, b.StartTime) })
+	return &sessions, nil
+}
+
+// decodeSessionsPage decodes a page of the sessions list one session at a
+// time, appending the ones matching filter to events. It returns the cursor
+// of the next page, and stops reading once filter.Max sessions were
+// collected.
+func decodeSessionsPage(r io.Reader, filter SessionFilter, events []Session) ([]Session, string, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return events, "", err
+	}
+
+	var nextToken string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return events, "", err
+		}
+		switch tok {
+		case "sessions":
+			tok, err := dec.Token()
+			if err != nil {
+				return events, "", err
+			}
+			if tok == nil {
+				// null is an empty list
+				continue
+			}
+			if tok != json.Delim('[') {
+				return events, "", fmt.Errorf("unexpected %v, expected a list of sessions", tok)
+			}
+			for dec.More() {
+				var session Session
+				if err := dec.Decode(&session); err != nil {
+					return events, "", err
+				}
+				if !filter.Match(session) {
+					continue
+				}
+				events = append(events, session)
+				if filter.Max > 0 && len(events) >= filter.Max {
+					return events, "", nil
+				}
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return events, "", err
+			}
+		case "next_token":
+			var token *string
+			if err := dec.Decode(&token); err != nil {
+				return events, "", err
+			}
+			if token != nil {
+				nextToken = *token
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return events, "", err
+			}
+		}
+	}
+	return events, nextToken, expectDelim(dec, '}')
+}
+
+// expectDelim reads the next JSON token, failing unless it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("unexpected %v, expected %v", tok, delim)
+	}
+	return nil
+}
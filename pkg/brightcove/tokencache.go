@@ -0,0 +1,82 @@
+package brightcove
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// TokenCache persists access tokens between runs so a still valid token does
+// not have to be requested again.
+type TokenCache interface {
+	// Get returns the cached token of clientID, or nil if there is none.
+	Get(clientID string) (*Token, error)
+	// Put stores token as the cached token of clientID.
+	Put(clientID string, token *Token) error
+}
+
+// FileTokenCache is a TokenCache storing the most recent token in a JSON file.
+type FileTokenCache struct {
+	Path string
+}
+
+type cachedToken struct {
+	ClientID string `json:"client_id"`
+	Token
+}
+
+// DefaultTokenCachePath returns the token cache location in the user's cache
+// directory, e.g. ~/.cache/bc-vod-urls/token.json on Linux.
+func DefaultTokenCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bc-vod-urls", "token.json"), nil
+}
+
+func (c FileTokenCache) Get(clientID string) (*Token, error) {
+	data, err := os.ReadFile(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading token cache: %w", err)
+	}
+
+	var cached cachedToken
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("error decoding token cache: %w", err)
+	}
+
+	// The cache only holds one token, ignore it if it belongs to other credentials
+	if cached.ClientID != clientID {
+		return nil, nil
+	}
+
+	return &cached.Token, nil
+}
+
+func (c FileTokenCache) Put(clientID string, token *Token) error {
+	data, err := json.Marshal(cachedToken{ClientID: clientID, Token: *token})
+	if err != nil {
+		return fmt.Errorf("error encoding token cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o700); err != nil {
+		return fmt.Errorf("error creating token cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so a concurrent run never reads a partial token
+	tmp := c.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("error writing token cache: %w", err)
+	}
+	if err := os.Rename(tmp, c.Path); err != nil {
+		return fmt.Errorf("error writing token cache: %w", err)
+	}
+
+	return nil
+}
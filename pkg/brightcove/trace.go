@@ -0,0 +1,20 @@
+package brightcove
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records a span per API operation. It is a no-op unless the
+// application registers an OpenTelemetry tracer provider.
+var tracer = otel.Tracer("github.com/rahulbalajee/bc-vod-urls/pkg/brightcove")
+
+// endSpan ends span, marking it failed if err is set.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,557 @@
+// Package brightcovetest provides a fake of the Brightcove OAuth and Live APIs
+// for integration tests and local development without real credentials.
+//
+//	srv := brightcovetest.NewServer()
+//	defer srv.Close()
+//	srv.AddSession(brightcove.Session{ID: "s1", AccountID: "123", ResourceID: "456", StartTime: start, EndTime: end})
+//
+//	client := srv.NewClient()
+//	sessions, err := client.GetResourceSessions(ctx, "123", "456")
+package brightcovetest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// Default credentials accepted by the server.
+const (
+	ClientID     = "test-client-id"
+	ClientSecret = "test-client-secret"
+)
+
+// Endpoints of the fake, as passed to Fail. They match the endpoint names the
+// client reports to a brightcove.Observer.
+const (
+	EndpointAccessToken    = brightcove.EndpointAccessToken
+	EndpointSessions       = "GET api.live.brightcove.com/v2/accounts/{id}/sessions/resource/{id}"
+	EndpointPlaybackToken  = brightcove.EndpointPlaybackToken
+	EndpointPlaybackURL    = brightcove.EndpointPlaybackURL
+	EndpointJobs           = "GET api.live.brightcove.com/v2/accounts/{id}/jobs"
+	EndpointJob            = "GET api.live.brightcove.com/v2/accounts/{id}/jobs/{id}"
+	EndpointJobClips       = "GET api.live.brightcove.com/v2/accounts/{id}/jobs/{id}/vods"
+	EndpointClip           = "GET api.live.brightcove.com/v2/accounts/{id}/vods/{id}"
+	EndpointDeleteClip     = "DELETE api.live.brightcove.com/v2/accounts/{id}/vods/{id}"
+	EndpointRedundantGroup = "GET api.live.brightcove.com/v2/accounts/{id}/redundantgroups/{id}"
+)
+
+// Server is a fake Brightcove API backed by fixtures. The zero value is not
+// usable, create one with NewServer. Its methods are safe to call while
+// requests are being served.
+type Server struct {
+	*httptest.Server
+
+	// ClientID and ClientSecret are the only credentials the server accepts.
+	ClientID     string
+	ClientSecret string
+	// PageSize is how many sessions or jobs a page of the sessions or jobs
+	// list holds, 0 returns them all on a single page.
+	PageSize int
+	// TokenTTL is the lifetime in seconds of the access tokens issued.
+	TokenTTL int
+
+	mu        sync.Mutex
+	sessions  map[string][]brightcove.Session
+	jobs      map[string]brightcove.Job
+	clips     map[string][]brightcove.Clip
+	groups    map[string]brightcove.RedundantGroup
+	alwaysOn  map[string]bool
+	failures  map[string][]failure
+	tokens    map[string]bool
+	playbacks map[string]brightcove.Session
+	requests  map[string]int
+	issued    int
+}
+
+type failure struct {
+	status int
+	body   string
+}
+
+// NewServer starts a fake Brightcove API without any sessions.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer returns a fake Brightcove API without any sessions that
+// isn't started yet, e.g. to set its Listener to a fixed address first. Call
+// Start once done.
+func NewUnstartedServer() *Server {
+	s := &Server{
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		TokenTTL:     300,
+		sessions:     map[string][]brightcove.Session{},
+		jobs:         map[string]brightcove.Job{},
+		clips:        map[string][]brightcove.Clip{},
+		groups:       map[string]brightcove.RedundantGroup{},
+		alwaysOn:     map[string]bool{},
+		failures:     map[string][]failure{},
+		tokens:       map[string]bool{},
+		playbacks:    map[string]brightcove.Session{},
+		requests:     map[string]int{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v4/access_token", s.handleAccessToken)
+	mux.HandleFunc("GET /v2/accounts/{account}/sessions/resource/{resource}", s.authorized(EndpointSessions, s.handleSessions))
+	mux.HandleFunc("POST /v2/accounts/{account}/playback/{resource}/token", s.authorized(EndpointPlaybackToken, s.handlePlaybackToken))
+	mux.HandleFunc("GET /v2/accounts/{account}/jobs", s.authorized(EndpointJobs, s.handleJobs))
+	mux.HandleFunc("GET /v2/accounts/{account}/jobs/{job}", s.authorized(EndpointJob, s.handleJob))
+	mux.HandleFunc("GET /v2/accounts/{account}/jobs/{job}/vods", s.authorized(EndpointJobClips, s.handleJobClips))
+	mux.HandleFunc("GET /v2/accounts/{account}/vods/{clip}", s.authorized(EndpointClip, s.handleClip))
+	mux.HandleFunc("DELETE /v2/accounts/{account}/vods/{clip}", s.authorized(EndpointDeleteClip, s.handleDeleteClip))
+	mux.HandleFunc("GET /v2/accounts/{account}/redundantgroups/{group}", s.authorized(EndpointRedundantGroup, s.handleRedundantGroup))
+	mux.HandleFunc("GET /v2/playback/{resource}", s.handlePlaybackURL)
+	mux.HandleFunc("GET /vod/{account}/{resource}/{session}/playlist.m3u8", s.handlePlaylist)
+	mux.HandleFunc("GET /vod/{account}/{resource}/{session}/{segment}", s.handleSegment)
+	s.Server = httptest.NewUnstartedServer(mux)
+
+	return s
+}
+
+// Client returns an HTTP client sending every request, whatever its host, to
+// the server. Pass it to brightcove.NewClient with brightcove.WithHTTPClient.
+func (s *Server) Client() *http.Client {
+	target, _ := url.Parse(s.URL)
+	return &http.Client{Transport: &rewriteTransport{target: target, base: s.Server.Client().Transport}}
+}
+
+// NewClient returns a brightcove.Client with the server's credentials, talking
+// to the server. opts are applied after those.
+func (s *Server) NewClient(opts ...brightcove.Option) *brightcove.Client {
+	opts = append([]brightcove.Option{
+		brightcove.WithCredentials(s.ClientID, s.ClientSecret),
+		brightcove.WithHTTPClient(s.Client()),
+	}, opts...)
+	return brightcove.NewClient(opts...)
+}
+
+// AddSession adds a session to the sessions list of its resource. A session
+// with EndTime 0 makes the resource live.
+func (s *Server) AddSession(session brightcove.Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := resourceKey(session.AccountID, session.ResourceID)
+	s.sessions[key] = append(s.sessions[key], session)
+}
+
+// SetAlwaysOn makes a resource a 24/7 channel: its live session doesn't
+// block playback tokens of the time it has already streamed.
+func (s *Server) SetAlwaysOn(accountID, resourceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alwaysOn[resourceKey(accountID, resourceID)] = true
+}
+
+// AddJob adds a live job returned by the jobs endpoint.
+func (s *Server) AddJob(job brightcove.Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[resourceKey(job.AccountID, job.ID)] = job
+}
+
+// AddRedundantGroup adds a redundant group. Its jobs may only hold their ID
+// when they were added with AddJob, and their sessions are added to the
+// resources of the jobs.
+func (s *Server) AddRedundantGroup(group brightcove.RedundantGroup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups[resourceKey(group.AccountID, group.ID)] = group
+}
+
+// AddClip adds a clip job to the clips of the live job jobID of the account.
+func (s *Server) AddClip(accountID, jobID string, clip brightcove.Clip) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := resourceKey(accountID, jobID)
+	s.clips[key] = append(s.clips[key], clip)
+}
+
+// Fail makes the next times calls of endpoint fail with status and body, e.g.
+// Fail(EndpointPlaybackToken, http.StatusServiceUnavailable, "", 2) to test
+// retries.
+func (s *Server) Fail(endpoint string, status int, body string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range times {
+		s.failures[endpoint] = append(s.failures[endpoint], failure{status: status, body: body})
+	}
+}
+
+// Requests returns how many calls of endpoint the server received, failed
+// ones included.
+func (s *Server) Requests(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[endpoint]
+}
+
+// RevokeTokens invalidates every access token issued so far, so the next API
+// call gets a 401.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.tokens)
+}
+
+// fail counts a call of endpoint and writes the failure queued for it, if any.
+func (s *Server) fail(w http.ResponseWriter, endpoint string) bool {
+	s.mu.Lock()
+	s.requests[endpoint]++
+	queued := s.failures[endpoint]
+	if len(queued) == 0 {
+		s.mu.Unlock()
+		return false
+	}
+	f := queued[0]
+	s.failures[endpoint] = queued[1:]
+	s.mu.Unlock()
+
+	w.WriteHeader(f.status)
+	fmt.Fprint(w, f.body)
+	return true
+}
+
+func (s *Server) handleAccessToken(w http.ResponseWriter, r *http.Request) {
+	if s.fail(w, EndpointAccessToken) {
+		return
+	}
+
+	if r.FormValue("grant_type") != "client_credentials" {
+		writeError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != s.ClientID || secret != s.ClientSecret {
+		writeError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	s.mu.Lock()
+	s.issued++
+	token := base64.RawURLEncoding.EncodeToString([]byte("access-token-" + strconv.Itoa(s.issued)))
+	s.tokens[token] = true
+	ttl := s.TokenTTL
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   ttl,
+	})
+}
+
+// authorized counts the call of endpoint and rejects it unless it carries an
+// access token issued by the server.
+func (s *Server) authorized(endpoint string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.fail(w, endpoint) {
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		valid := ok && s.tokens[token]
+		s.mu.Unlock()
+		if !valid {
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	sessions := s.sessions[resourceKey(r.PathValue("account"), r.PathValue("resource"))]
+	pageSize := s.PageSize
+	s.mu.Unlock()
+
+	// The cursor is the offset of the page as a string
+	start := 0
+	if cursor := r.URL.Query().Get("start_token"); cursor != "" {
+		var err error
+		if start, err = strconv.Atoi(cursor); err != nil || start < 0 || start > len(sessions) {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST")
+			return
+		}
+	}
+	end := len(sessions)
+	if pageSize > 0 {
+		end = min(start+pageSize, len(sessions))
+	}
+
+	page := brightcove.Sessions{Events: sessions[start:end]}
+	if page.Events == nil {
+		page.Events = []brightcove.Session{}
+	}
+	if end < len(sessions) {
+		page.NextToken = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) handlePlaybackToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		StartTime      string `json:"start_time"`
+		EndTime        string `json:"end_time"`
+		ManifestFormat string `json:"manifest_format"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST")
+		return
+	}
+	if req.ManifestFormat != brightcove.ManifestFormatHLS && req.ManifestFormat != brightcove.ManifestFormatDASH {
+		writeError(w, http.StatusBadRequest, "INVALID_MANIFEST_FORMAT")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := resourceKey(r.PathValue("account"), r.PathValue("resource"))
+	sessions := s.sessions[key]
+	if len(sessions) == 0 {
+		writeError(w, http.StatusNotFound, "NOT_FOUND")
+		return
+	}
+	// Like the real API, no VOD is available while the resource is live,
+	// unless it is a channel
+	now := int(time.Now().Unix())
+	for _, session := range sessions {
+		if session.EndTime == 0 && !s.alwaysOn[key] {
+			writeError(w, http.StatusConflict, "RESOURCE_IS_LIVE")
+			return
+		}
+	}
+
+	start, _ := strconv.Atoi(req.StartTime)
+	end, _ := strconv.Atoi(req.EndTime)
+	var session *brightcove.Session
+	for i := range sessions {
+		// The live session of a channel holds what it streamed so far
+		sessionEnd := sessions[i].EndTime
+		if sessionEnd == 0 {
+			sessionEnd = now
+		}
+		if start >= sessions[i].StartTime && end <= sessionEnd && start < end {
+			session = &sessions[i]
+			break
+		}
+	}
+	if session == nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TIME_RANGE")
+		return
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%s:%s:%d:%d:%s", session.ResourceID, session.ID, start, end, req.ManifestFormat))
+	s.playbacks[token] = *session
+	writeJSON(w, http.StatusOK, brightcove.PlaybackToken{Token: token})
+}
+
+func (s *Server) handlePlaybackURL(w http.ResponseWriter, r *http.Request) {
+	if s.fail(w, EndpointPlaybackURL) {
+		return
+	}
+
+	token := r.URL.Query().Get("pt")
+	s.mu.Lock()
+	session, ok := s.playbacks[token]
+	s.mu.Unlock()
+	if !ok || session.ResourceID != r.PathValue("resource") {
+		writeError(w, http.StatusForbidden, "INVALID_PLAYBACK_TOKEN")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, brightcove.PlaybackURL{
+		URL: fmt.Sprintf("%s/vod/%s/%s/%s/playlist.m3u8?pt=%s", s.URL, session.AccountID, session.ResourceID, session.ID, url.QueryEscape(token)),
+	})
+}
+
+// handlePlaylist serves an HLS VOD playlist of the session with a 10 second
+// segment per minute of the session, so generated URLs can be verified,
+// inspected and downloaded.
+func (s *Server) handlePlaylist(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	session, ok := s.playbacks[r.URL.Query().Get("pt")]
+	s.mu.Unlock()
+	if !ok || session.ID != r.PathValue("session") {
+		writeError(w, http.StatusForbidden, "INVALID_PLAYBACK_TOKEN")
+		return
+	}
+
+	segments := max(1, (session.EndTime-session.StartTime)/60)
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:10\n#EXT-X-PLAYLIST-TYPE:VOD\n")
+	for i := range segments {
+		fmt.Fprintf(&b, "#EXTINF:10.000,\nsegment%d.ts\n", i)
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	fmt.Fprint(w, b.String())
+}
+
+// handleSegment serves a single empty MPEG-TS packet as every segment.
+func (s *Server) handleSegment(w http.ResponseWriter, r *http.Request) {
+	packet := make([]byte, 188)
+	packet[0] = 0x47
+	w.Header().Set("Content-Type", "video/mp2t")
+	w.Write(packet)
+}
+
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	job, ok := s.jobs[resourceKey(r.PathValue("account"), r.PathValue("job"))]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND")
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleJobs lists the jobs of an account sorted by ID, paginated like the
+// sessions list.
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	var jobs []brightcove.Job
+	for _, job := range s.jobs {
+		if job.AccountID == r.PathValue("account") {
+			jobs = append(jobs, job)
+		}
+	}
+	pageSize := s.PageSize
+	s.mu.Unlock()
+	slices.SortFunc(jobs, func(a, b brightcove.Job) int { return strings.Compare(a.ID, b.ID) })
+
+	start := 0
+	if cursor := r.URL.Query().Get("start_token"); cursor != "" {
+		var err error
+		if start, err = strconv.Atoi(cursor); err != nil || start < 0 || start > len(jobs) {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST")
+			return
+		}
+	}
+	end := len(jobs)
+	if pageSize > 0 {
+		end = min(start+pageSize, len(jobs))
+	}
+
+	page := struct {
+		Jobs      []brightcove.Job `json:"jobs"`
+		NextToken string           `json:"next_token,omitempty"`
+	}{Jobs: append([]brightcove.Job{}, jobs[start:end]...)}
+	if end < len(jobs) {
+		page.NextToken = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) handleRedundantGroup(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	group, ok := s.groups[resourceKey(r.PathValue("account"), r.PathValue("group"))]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND")
+		return
+	}
+	writeJSON(w, http.StatusOK, group)
+}
+
+func (s *Server) handleJobClips(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	key := resourceKey(r.PathValue("account"), r.PathValue("job"))
+	_, ok := s.jobs[key]
+	clips := s.clips[key]
+	s.mu.Unlock()
+	if !ok && clips == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND")
+		return
+	}
+	if clips == nil {
+		clips = []brightcove.Clip{}
+	}
+	writeJSON(w, http.StatusOK, clips)
+}
+
+func (s *Server) handleClip(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, i := s.findClip(r.PathValue("account"), r.PathValue("clip"))
+	if i == -1 {
+		writeError(w, http.StatusNotFound, "NOT_FOUND")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.clips[key][i])
+}
+
+func (s *Server) handleDeleteClip(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, i := s.findClip(r.PathValue("account"), r.PathValue("clip"))
+	if i == -1 {
+		writeError(w, http.StatusNotFound, "NOT_FOUND")
+		return
+	}
+	s.clips[key] = slices.Delete(s.clips[key], i, i+1)
+	writeJSON(w, http.StatusOK, map[string]string{"jvod_id": r.PathValue("clip")})
+}
+
+// findClip returns the key of the clips holding the clip of the account and
+// its index, -1 if there is none. s.mu must be held.
+func (s *Server) findClip(accountID, clipID string) (string, int) {
+	for key, clips := range s.clips {
+		if !strings.HasPrefix(key, accountID+"/") {
+			continue
+		}
+		if i := slices.IndexFunc(clips, func(c brightcove.Clip) bool { return c.ID == clipID }); i != -1 {
+			return key, i
+		}
+	}
+	return "", -1
+}
+
+func resourceKey(accountID, id string) string {
+	return accountID + "/" + id
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the shape of the Brightcove APIs.
+func writeError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, []map[string]string{{"error_code": code}})
+}
+
+// rewriteTransport sends every request to target instead of its own host.
+type rewriteTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.Host = t.target.Host
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,5 @@
+// Package vodurlspb is the generated client and server code of the gRPC API
+// served by vodurls serve --grpc-addr, defined in vodurls.proto.
+package vodurlspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative vodurls.proto
@@ -0,0 +1,177 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: vodurls.proto
+
+// The gRPC API of `vodurls serve --grpc-addr`, mirroring POST /v1/vod-urls.
+
+package vodurlspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	VODURLService_GenerateVODURLs_FullMethodName      = "/vodurls.v1.VODURLService/GenerateVODURLs"
+	VODURLService_GenerateVODURLsBatch_FullMethodName = "/vodurls.v1.VODURLService/GenerateVODURLsBatch"
+)
+
+// VODURLServiceClient is the client API for VODURLService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// VODURLService generates VOD URLs of the sessions of NextGenLive resources.
+type VODURLServiceClient interface {
+	// GenerateVODURLs generates the VOD URLs of a single resource.
+	GenerateVODURLs(ctx context.Context, in *GenerateVODURLsRequest, opts ...grpc.CallOption) (*GenerateVODURLsResponse, error)
+	// GenerateVODURLsBatch generates the VOD URLs of several resources,
+	// streaming the result of each as soon as it is done. A failing resource
+	// is reported in its result without ending the stream.
+	GenerateVODURLsBatch(ctx context.Context, in *GenerateVODURLsBatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GenerateVODURLsBatchResponse], error)
+}
+
+type vODURLServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVODURLServiceClient(cc grpc.ClientConnInterface) VODURLServiceClient {
+	return &vODURLServiceClient{cc}
+}
+
+func (c *vODURLServiceClient) GenerateVODURLs(ctx context.Context, in *GenerateVODURLsRequest, opts ...grpc.CallOption) (*GenerateVODURLsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenerateVODURLsResponse)
+	err := c.cc.Invoke(ctx, VODURLService_GenerateVODURLs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vODURLServiceClient) GenerateVODURLsBatch(ctx context.Context, in *GenerateVODURLsBatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GenerateVODURLsBatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VODURLService_ServiceDesc.Streams[0], VODURLService_GenerateVODURLsBatch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GenerateVODURLsBatchRequest, GenerateVODURLsBatchResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VODURLService_GenerateVODURLsBatchClient = grpc.ServerStreamingClient[GenerateVODURLsBatchResponse]
+
+// VODURLServiceServer is the server API for VODURLService service.
+// All implementations must embed UnimplementedVODURLServiceServer
+// for forward compatibility.
+//
+// VODURLService generates VOD URLs of the sessions of NextGenLive resources.
+type VODURLServiceServer interface {
+	// GenerateVODURLs generates the VOD URLs of a single resource.
+	GenerateVODURLs(context.Context, *GenerateVODURLsRequest) (*GenerateVODURLsResponse, error)
+	// GenerateVODURLsBatch generates the VOD URLs of several resources,
+	// streaming the result of each as soon as it is done. A failing resource
+	// is reported in its result without ending the stream.
+	GenerateVODURLsBatch(*GenerateVODURLsBatchRequest, grpc.ServerStreamingServer[GenerateVODURLsBatchResponse]) error
+	mustEmbedUnimplementedVODURLServiceServer()
+}
+
+// UnimplementedVODURLServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVODURLServiceServer struct{}
+
+func (UnimplementedVODURLServiceServer) GenerateVODURLs(context.Context, *GenerateVODURLsRequest) (*GenerateVODURLsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateVODURLs not implemented")
+}
+func (UnimplementedVODURLServiceServer) GenerateVODURLsBatch(*GenerateVODURLsBatchRequest, grpc.ServerStreamingServer[GenerateVODURLsBatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method GenerateVODURLsBatch not implemented")
+}
+func (UnimplementedVODURLServiceServer) mustEmbedUnimplementedVODURLServiceServer() {}
+func (UnimplementedVODURLServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeVODURLServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VODURLServiceServer will
+// result in compilation errors.
+type UnsafeVODURLServiceServer interface {
+	mustEmbedUnimplementedVODURLServiceServer()
+}
+
+func RegisterVODURLServiceServer(s grpc.ServiceRegistrar, srv VODURLServiceServer) {
+	// If the following call pancis, it indicates UnimplementedVODURLServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VODURLService_ServiceDesc, srv)
+}
+
+func _VODURLService_GenerateVODURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateVODURLsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VODURLServiceServer).GenerateVODURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VODURLService_GenerateVODURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VODURLServiceServer).GenerateVODURLs(ctx, req.(*GenerateVODURLsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VODURLService_GenerateVODURLsBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GenerateVODURLsBatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VODURLServiceServer).GenerateVODURLsBatch(m, &grpc.GenericServerStream[GenerateVODURLsBatchRequest, GenerateVODURLsBatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VODURLService_GenerateVODURLsBatchServer = grpc.ServerStreamingServer[GenerateVODURLsBatchResponse]
+
+// VODURLService_ServiceDesc is the grpc.ServiceDesc for VODURLService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VODURLService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vodurls.v1.VODURLService",
+	HandlerType: (*VODURLServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateVODURLs",
+			Handler:    _VODURLService_GenerateVODURLs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GenerateVODURLsBatch",
+			Handler:       _VODURLService_GenerateVODURLsBatch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "vodurls.proto",
+}
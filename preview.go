@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// previewPage is the page the preview command serves, holding the embed
+// snippet of every generated URL.
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>VOD preview of resource {{.ResourceID}}</title>
+<style>body{font-family:system-ui,sans-serif;margin:2em}figure{margin:0 0 2em}</style>
+</head>
+<body>
+<h1>Resource {{.ResourceID}} of account {{.AccountID}}</h1>
+{{.Players}}
+</body>
+</html>
+`))
+
+type previewData struct {
+	AccountID  string
+	ResourceID string
+	Players    template.HTML
+}
+
+func runPreview(args []string) {
+	fs := flag.NewFlagSet("vodurls preview", flag.ExitOnError)
+	setUsage(fs, "Generates VOD URLs and serves a local page playing them, to check the recordings.",
+		"./vodurls preview [flags] <PLAYBACK_URL>",
+		"./vodurls preview [flags] --account ID --resource ID",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	var rf resourceFlags
+	rf.register(fs)
+	format := fs.String("format", "", "manifest format of the previewed VOD URLs (hls, dash or both), defaults to the format of the playback URL")
+	var sessionIDs stringList
+	fs.Var(&sessionIDs, "session", "only preview the session with this ID (repeatable)")
+	sessionIndex := -1
+	fs.Var((*indexFlag)(&sessionIndex), "session-index", "only preview the session at this 0-based position in the resource's session list")
+	latest := fs.Int("latest", 0, "only preview the N most recent ended sessions")
+	addr := fs.String("addr", "localhost:0", "address to serve the preview page on, a random port by default")
+	open := fs.Bool("open", false, "open the preview page in the default browser")
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() == 0 && !rf.set() || fs.NArg() > 1 {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	formats, err := parseFormat(*format)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	selection := sessionSelection{ids: sessionIDs, index: sessionIndex, latest: *latest}
+	if err := selection.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	accountID, resourceID, err := rf.resolve(ctx, client, cf.profile.AccountID, fs.Arg(0))
+	if err != nil {
+		slog.Error("error getting sessions", "err", err)
+		exit(exitCode(err))
+	}
+
+	opts := generateOptions{
+		formats:   inferFormats(formats, fs.Arg(0)),
+		selection: selection,
+	}
+	results, err := generate(ctx, client, accountID, resourceID, opts)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(exitCode(err))
+	}
+
+	var players bytes.Buffer
+	if err := writeEmbed(&players, results); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	var page bytes.Buffer
+	err = previewPage.Execute(&page, previewData{AccountID: accountID, ResourceID: resourceID, Players: template.HTML(players.String())})
+	if err != nil {
+		slog.Error("error rendering preview page", "err", err)
+		exit(1)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		slog.Error("error listening", "err", err)
+		exit(1)
+	}
+	pageURL := "http://" + lis.Addr().String() + "/"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page.Bytes())
+	})
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	// Serve until interrupted, not for the --timeout of the generation
+	serveCtx, stop := signalContext()
+	defer stop()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(lis)
+	}()
+
+	slog.Info("serving preview, press Ctrl+C to stop", "url", pageURL, "sessions", len(results))
+	if *open {
+		if err := openBrowser(pageURL); err != nil {
+			slog.Warn("error opening browser", "err", err)
+		}
+	}
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("error serving preview", "err", err)
+			exit(1)
+		}
+	case <-serveCtx.Done():
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	httpServer.Shutdown(shutdownCtx)
+}
+
+// openBrowser opens url in the default browser of the OS.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
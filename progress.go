@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	progressBarWidth = 30
+	// progressLogInterval is how often the progress is logged when stderr
+	// isn't a terminal.
+	progressLogInterval = 30 * time.Second
+)
+
+// progress reports how far a batch run got, as a status line redrawn in place
+// when stderr is a terminal and as periodic log lines otherwise.
+type progress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	tty     bool
+	total   int
+	done    int
+	failed  int
+	start   time.Time
+	lastLog time.Time
+	drawn   bool
+}
+
+func newProgress(total int) *progress {
+	return &progress{
+		w:     os.Stderr,
+		tty:   term.IsTerminal(int(os.Stderr.Fd())),
+		total: total,
+		start: time.Now(),
+	}
+}
+
+// add records a processed item.
+func (p *progress) add(ctx context.Context, failed bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	if failed {
+		p.failed++
+	}
+
+	if p.tty {
+		p.draw()
+		return
+	}
+	if p.done == p.total || time.Since(p.lastLog) >= progressLogInterval {
+		p.lastLog = time.Now()
+		slog.InfoContext(ctx, "progress", "done", p.done, "total", p.total, "failed", p.failed, "eta", p.eta().Round(time.Second))
+	}
+}
+
+// finish ends the status line so later output starts on a line of its own.
+func (p *progress) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+}
+
+func (p *progress) eta() time.Duration {
+	if p.done == 0 {
+		return 0
+	}
+	perItem := time.Since(p.start) / time.Duration(p.done)
+	return perItem * time.Duration(p.total-p.done)
+}
+
+func (p *progress) draw() {
+	filled := progressBarWidth * p.done / p.total
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+	fmt.Fprintf(p.w, "\r\033[K[%s] %d/%d, %d failed, ETA %s", bar, p.done, p.total, p.failed, p.eta().Round(time.Second))
+	p.drawn = true
+}
+
+func (p *progress) clear() {
+	if p.drawn {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.drawn = false
+	}
+}
+
+// handler wraps a log handler so log lines don't get mixed into the status
+// line: it is cleared before every record and redrawn after it.
+func (p *progress) handler(h slog.Handler) slog.Handler {
+	if !p.tty {
+		return h
+	}
+	return &progressHandler{Handler: h, progress: p}
+}
+
+type progressHandler struct {
+	slog.Handler
+	progress *progress
+}
+
+func (h *progressHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.progress.mu.Lock()
+	defer h.progress.mu.Unlock()
+
+	redraw := h.progress.drawn
+	h.progress.clear()
+	err := h.Handler.Handle(ctx, r)
+	if redraw {
+		h.progress.draw()
+	}
+	return err
+}
+
+func (h *progressHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &progressHandler{Handler: h.Handler.WithAttrs(attrs), progress: h.progress}
+}
+
+func (h *progressHandler) WithGroup(name string) slog.Handler {
+	return &progressHandler{Handler: h.Handler.WithGroup(name), progress: h.progress}
+}
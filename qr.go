@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// qrTerminal is the --qr value printing the QR codes in the terminal.
+const qrTerminal = "-"
+
+// writeQRCodes renders a QR code of every URL, its short link when there is
+// one as it scans more reliably. With dir set to qrTerminal the codes are
+// printed to stderr, keeping stdout for the regular output, otherwise they are
+// written to dir as <resource>-<session>-<format>.png.
+func writeQRCodes(dir string, results []sessionResult) error {
+	if dir != qrTerminal {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("error creating QR code directory: %w", err)
+		}
+	}
+
+	for _, result := range results {
+		for _, url := range result.URLs {
+			content := url.URL
+			if url.ShortURL != "" {
+				content = url.ShortURL
+			}
+
+			if dir == qrTerminal {
+				code, err := qrcode.New(content, qrcode.Low)
+				if err != nil {
+					return fmt.Errorf("error encoding QR code: %w", err)
+				}
+				fmt.Fprintf(os.Stderr, "\nSession %s (%s):\n%s", result.SessionID, url.Format, code.ToSmallString(false))
+				continue
+			}
+
+			path := filepath.Join(dir, fmt.Sprintf("%s-%s-%s.png", result.ResourceID, result.SessionID, url.Format))
+			if err := qrcode.WriteFile(content, qrcode.Medium, 512, path); err != nil {
+				return fmt.Errorf("error writing QR code: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// groupLeg is a job of a redundant group and the resource it records to.
+type groupLeg struct {
+	jobID      string
+	resourceID string
+}
+
+// generateGroup runs the VOD generation flow for a redundant group: the
+// sessions of the resources of all its jobs are combined, and of the sessions
+// several legs recorded at the same time only the longest recording is kept.
+// The VOD URLs of every session are generated with the resource of the leg
+// that recorded it.
+func generateGroup(ctx context.Context, client *brightcove.Client, accountID, groupID string, opts generateOptions) ([]sessionResult, error) {
+	legs, err := groupLegs(ctx, client, accountID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	combined := &brightcove.Sessions{}
+	for _, leg := range legs {
+		sessions, err := getEndedSessions(ctx, client, accountID, leg.resourceID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("leg %s of redundant group %s: %w", leg.jobID, groupID, err)
+		}
+		for _, session := range sessions.Events {
+			// Results name the leg's resource, and its job unless the
+			// session reports one
+			session.ResourceID = leg.resourceID
+			session.JobID = cmp.Or(session.JobID, leg.jobID)
+			combined.Events = append(combined.Events, session)
+		}
+	}
+	slices.SortStableFunc(combined.Events, func(a, b brightcove.Session) int { return a.StartTime - b.StartTime })
+
+	// The live sessions of a channel only overlap once cut to the window
+	if opts.channel == nil {
+		combined = pickLegs(ctx, combined)
+	}
+	combined, err = opts.selectSessions(combined)
+	if err != nil {
+		return nil, err
+	}
+	if opts.channel != nil {
+		combined = pickLegs(ctx, combined)
+	}
+
+	var results, skipped []sessionResult
+	var expired error
+	for _, leg := range legs {
+		sessions := &brightcove.Sessions{}
+		for _, session := range combined.Events {
+			if session.ResourceID == leg.resourceID {
+				sessions.Events = append(sessions.Events, session)
+			}
+		}
+		if len(sessions.Events) == 0 {
+			continue
+		}
+
+		legResults, err := generateSessions(ctx, client, accountID, leg.resourceID, sessions, opts)
+		// Only the sessions of other legs may be inside the VOD window
+		if errors.Is(err, brightcove.ErrVODWindowExpired) {
+			expired = err
+			skipped = append(skipped, legResults...)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("leg %s of redundant group %s: %w", leg.jobID, groupID, err)
+		}
+		results = append(results, legResults...)
+	}
+	if len(results) == 0 && expired != nil {
+		return skipped, expired
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("error creating playback token: redundant group %s: %w, quitting", groupID, brightcove.ErrNoSessions)
+	}
+	results = append(results, skipped...)
+	slices.SortStableFunc(results, func(a, b sessionResult) int { return a.StartTime - b.StartTime })
+
+	return results, nil
+}
+
+// groupLegs returns the jobs of a redundant group with their resources,
+// fetching the jobs the group only reports the ID of.
+func groupLegs(ctx context.Context, client *brightcove.Client, accountID, groupID string) ([]groupLeg, error) {
+	group, err := client.GetRedundantGroup(ctx, accountID, groupID)
+	if err != nil {
+		return nil, withExitCode(exitAPI, fmt.Errorf("error getting redundant group %s: %w", groupID, err))
+	}
+
+	var legs []groupLeg
+	for _, job := range group.Jobs {
+		if _, _, err := job.Resource(); err != nil {
+			fetched, err := client.GetJob(ctx, accountID, job.ID)
+			if err != nil {
+				return nil, withExitCode(exitAPI, fmt.Errorf("error getting job %s of redundant group %s: %w", job.ID, groupID, err))
+			}
+			job = *fetched
+		}
+		_, resourceID, err := job.Resource()
+		if err != nil {
+			return nil, fmt.Errorf("job %s of redundant group %s: %w", job.ID, groupID, err)
+		}
+		// The playback URL of the job tells the region of its resource
+		if job.PlaybackURL != "" {
+			client.ResolvePlaybackURL(job.PlaybackURL)
+		}
+		legs = append(legs, groupLeg{jobID: job.ID, resourceID: resourceID})
+	}
+	if len(legs) == 0 {
+		return nil, fmt.Errorf("redundant group %s has no jobs", groupID)
+	}
+
+	slog.DebugContext(ctx, "resolved redundant group", "group_id", groupID, "legs", len(legs))
+	return legs, nil
+}
+
+// pickLegs keeps, of the sessions of different legs whose time ranges
+// overlap, i.e. recordings of the same content, the longest one. Overlapping
+// sessions of the same leg are left to --dedupe-overlaps.
+func pickLegs(ctx context.Context, sessions *brightcove.Sessions) *brightcove.Sessions {
+	dropped := map[string]bool{}
+	for _, group := range overlappingSessions(sessions.Events) {
+		longest := group[0]
+		for _, session := range group {
+			if session.EndTime-session.StartTime > longest.EndTime-longest.StartTime {
+				longest = session
+			}
+		}
+		for _, session := range group {
+			if session.ResourceID != longest.ResourceID {
+				dropped[session.ID] = true
+			}
+		}
+		slog.DebugContext(ctx, "legs recorded the same session, keeping the longest recording", "kept_session_id", longest.ID, "resource_id", longest.ResourceID)
+	}
+	if len(dropped) == 0 {
+		return sessions
+	}
+
+	kept := &brightcove.Sessions{}
+	for _, session := range sessions.Events {
+		if !dropped[session.ID] {
+			kept.Events = append(kept.Events, session)
+		}
+	}
+	return kept
+}
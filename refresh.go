@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/history"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// refreshedURL is a VOD URL regenerated by refresh, as published to the
+// webhook.
+type refreshedURL struct {
+	AccountID   string    `json:"account_id"`
+	ResourceID  string    `json:"resource_id"`
+	SessionID   string    `json:"session_id"`
+	Format      string    `json:"format"`
+	URL         string    `json:"url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	PreviousURL string    `json:"previous_url"`
+}
+
+// refresher regenerates the VOD URLs of the history before their tokens
+// expire.
+type refresher struct {
+	client     *brightcove.Client
+	store      *history.Store
+	audit      *auditLog
+	httpClient *http.Client
+	webhook    string
+	before     time.Duration
+	notifiers  []notifier
+}
+
+func runRefresh(args []string) {
+	fs := flag.NewFlagSet("vodurls refresh", flag.ExitOnError)
+	setUsage(fs, "Regenerates the VOD URLs of the history before their playback tokens expire and publishes them to a webhook.",
+		"./vodurls refresh --webhook URL [--db PATH] [--interval 5m] [--before 1h]",
+		"./vodurls refresh --webhook URL --once",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	var af auditFlags
+	af.register(fs)
+	var nf notifyFlags
+	nf.register(fs)
+	db := fs.String("db", envString("HISTORY_DB", defaultHistoryDB), "history database of the URLs to refresh (env HISTORY_DB)")
+	webhook := fs.String("webhook", envString("REFRESH_WEBHOOK", ""), "URL the refreshed VOD URLs are POSTed to as JSON (env REFRESH_WEBHOOK)")
+	interval := fs.Duration("interval", 5*time.Minute, "how often to look for URLs about to expire")
+	before := fs.Duration("before", time.Hour, "refresh URLs whose token expires within this long")
+	once := fs.Bool("once", false, "refresh once and exit instead of running until interrupted, e.g. from cron")
+	shutdownTimeout := fs.Duration("shutdown-timeout", envDuration("SHUTDOWN_TIMEOUT", defaultShutdownTimeout), "on SIGINT or SIGTERM, how long the round in progress gets to complete before it is cancelled (env SHUTDOWN_TIMEOUT)")
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if *webhook == "" {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if *interval <= 0 || *before <= 0 {
+		slog.Error("--interval and --before must be positive", "interval", *interval, "before", *before)
+		exit(1)
+	}
+
+	notifiers, err := nf.notifiers(&cf)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	store, err := history.Open(expandHome(*db))
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	defer store.Close()
+
+	auditLog, err := af.open()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	if auditLog != nil {
+		defer auditLog.Close()
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	r := &refresher{
+		client:     client,
+		store:      store,
+		audit:      auditLog,
+		httpClient: cf.httpClient(),
+		webhook:    *webhook,
+		before:     *before,
+		notifiers:  notifiers,
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	// A round in progress is finished rather than cut short by a signal
+	roundCtx, cancel := drainContext(ctx, *shutdownTimeout)
+	defer cancel()
+
+	if *once {
+		if err := r.refresh(roundCtx); err != nil {
+			slog.Error(err.Error())
+			exit(exitCode(err))
+		}
+		return
+	}
+
+	slog.Info("refreshing VOD URLs", "interval", *interval, "before", *before)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		// A failed round is retried on the next tick
+		if err := r.refresh(roundCtx); err != nil && roundCtx.Err() == nil {
+			slog.Error(err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh regenerates every URL expiring within r.before and publishes them.
+// URLs are only recorded in the history once published, so a failed webhook
+// call is retried on the next round.
+func (r *refresher) refresh(ctx context.Context) error {
+	// Bound a round so a stuck API call doesn't stall the daemon
+	ctx, cancel := context.WithTimeout(ctx, max(r.before/2, time.Minute))
+	defer cancel()
+
+	entries, err := r.store.Expiring(ctx, time.Now().Add(r.before))
+	if err != nil {
+		return err
+	}
+
+	var refreshed []refreshedURL
+	var results []sessionResult
+	var failed int
+	for _, entry := range entries {
+		session := brightcove.Session{
+			ID:         entry.SessionID,
+			AccountID:  entry.AccountID,
+			ResourceID: entry.ResourceID,
+			StartTime:  entry.StartTime,
+			EndTime:    entry.EndTime,
+		}
+		// Past the end of the VOD window a new token wouldn't live any longer
+		if !entry.ExpiresAt.Before(session.VODExpiry(r.client.VODWindowDays)) || !session.WithinVODWindow(r.client.VODWindowDays) {
+			continue
+		}
+
+		result, err := r.regenerate(ctx, session, entry.Format)
+		if err != nil {
+			slog.ErrorContext(ctx, "error refreshing VOD URL", "session_id", entry.SessionID, "format", entry.Format, "err", err)
+			failed++
+			continue
+		}
+		results = append(results, result)
+		url := result.URLs[0]
+		refreshed = append(refreshed, refreshedURL{
+			AccountID:   entry.AccountID,
+			ResourceID:  entry.ResourceID,
+			SessionID:   entry.SessionID,
+			Format:      entry.Format,
+			URL:         url.URL,
+			ExpiresAt:   url.ExpiresAt,
+			PreviousURL: entry.URL,
+		})
+	}
+
+	if len(refreshed) > 0 {
+		if err := r.publish(ctx, refreshed); err != nil {
+			return err
+		}
+		recordHistory(ctx, r.store, results)
+		notifyAll(ctx, r.notifiers, groupByResource(results))
+	}
+	slog.InfoContext(ctx, "refreshed VOD URLs", "refreshed", len(refreshed), "failed", failed)
+
+	if failed > 0 {
+		return withExitCode(exitPartial, fmt.Errorf("%d of %d VOD URLs could not be refreshed", failed, failed+len(refreshed)))
+	}
+	return nil
+}
+
+// regenerate requests a new playback token and URL for session.
+func (r *refresher) regenerate(ctx context.Context, session brightcove.Session, format string) (sessionResult, error) {
+	tokens, err := r.client.GeneratePlaybackTokens(ctx, &brightcove.Sessions{Events: []brightcove.Session{session}}, format)
+	if err != nil {
+		return sessionResult{}, fmt.Errorf("error creating playback token: %w", err)
+	}
+	playbackURLs, err := r.client.GeneratePlaybackURLs(ctx, tokens, session.ResourceID)
+	if err != nil {
+		return sessionResult{}, fmt.Errorf("error generating playback url: %w", err)
+	}
+
+	results := groupBySession(playbackURLs, r.client.VODWindowDays)
+	if r.audit != nil {
+		if err := r.audit.record(results); err != nil {
+			return sessionResult{}, err
+		}
+	}
+	return results[0], nil
+}
+
+// publish POSTs the refreshed URLs to the webhook.
+func (r *refresher) publish(ctx context.Context, refreshed []refreshedURL) error {
+	if err := postJSON(ctx, r.httpClient, r.webhook, refreshed, nil); err != nil {
+		return fmt.Errorf("error calling webhook: %w", err)
+	}
+	return nil
+}
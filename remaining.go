@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// dayDuration is a duration flag that also accepts a number of days, e.g. 2d
+// or 1d12h, for spans of the VOD window.
+type dayDuration time.Duration
+
+func (d *dayDuration) String() string {
+	return formatDays(time.Duration(*d))
+}
+
+func (d *dayDuration) Set(value string) error {
+	parsed, err := parseDays(value)
+	if err != nil {
+		return err
+	}
+	*d = dayDuration(parsed)
+	return nil
+}
+
+// parseDays parses a duration of time.ParseDuration, optionally prefixed with
+// a number of days, e.g. 2d, 1d12h or 36h.
+func parseDays(value string) (time.Duration, error) {
+	days, rest, ok := strings.Cut(value, "d")
+	if !ok {
+		return time.ParseDuration(value)
+	}
+	n, err := strconv.Atoi(days)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q, expected e.g. 2d, 1d12h or 36h", value)
+	}
+	d := time.Duration(n) * 24 * time.Hour
+	if rest != "" {
+		r, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q, expected e.g. 2d, 1d12h or 36h", value)
+		}
+		d += r
+	}
+	return d, nil
+}
+
+// formatDays renders d in days and hours, or hours and minutes below a day,
+// e.g. 4d3h or 5h20m.
+func formatDays(d time.Duration) string {
+	if d <= 0 {
+		return "0m"
+	}
+	days, hours, minutes := int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour), int(d%time.Hour/time.Minute)
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd", days)
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// skipExpiring drops the ended sessions whose VOD window closes within
+// minRemaining, as URLs of them would hardly be usable. It returns how many
+// were dropped, and an error when no ended session is left.
+func skipExpiring(ctx context.Context, sessions *brightcove.Sessions, vodWindowDays int, minRemaining time.Duration) (*brightcove.Sessions, int, error) {
+	if minRemaining <= 0 {
+		return sessions, 0, nil
+	}
+
+	kept := &brightcove.Sessions{}
+	var ended, dropped int
+	for _, session := range sessions.Events {
+		if session.EndTime != 0 {
+			ended++
+			if remaining := time.Until(session.VODExpiry(vodWindowDays)); remaining < minRemaining {
+				slog.InfoContext(ctx, "skipping session about to leave the VOD window", "session_id", session.ID, "vod_remaining", formatDays(remaining), "min_remaining", formatDays(minRemaining))
+				dropped++
+				continue
+			}
+		}
+		kept.Events = append(kept.Events, session)
+	}
+	if ended > 0 && dropped == ended {
+		return nil, dropped, withExitCode(exitExpired, fmt.Errorf("no session has %s of its VOD window left", formatDays(minRemaining)))
+	}
+	return kept, dropped, nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/hls"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// errNoRendition means a master playlist has no rendition matching the
+// --max-resolution or --audio-only selection.
+var errNoRendition = errors.New("no matching rendition")
+
+// renditionSelection picks the rendition of HLS VODs whose media playlist URL
+// is emitted instead of the master playlist URL.
+type renditionSelection struct {
+	// maxHeight keeps the video renditions at most this many lines high.
+	maxHeight int
+	audioOnly bool
+	// httpClient fetches the master playlists.
+	httpClient *http.Client
+}
+
+// resolutionPattern matches --max-resolution values, e.g. 720p or 1280x720.
+var resolutionPattern = regexp.MustCompile(`^(?:(\d+)p|\d+x(\d+))$`)
+
+func parseRenditionSelection(maxResolution string, audioOnly bool) (*renditionSelection, error) {
+	if maxResolution == "" && !audioOnly {
+		return nil, nil
+	}
+	if maxResolution != "" && audioOnly {
+		return nil, errors.New("--max-resolution and --audio-only are mutually exclusive")
+	}
+	if audioOnly {
+		return &renditionSelection{audioOnly: true}, nil
+	}
+
+	m := resolutionPattern.FindStringSubmatch(strings.ToLower(maxResolution))
+	if m == nil {
+		return nil, fmt.Errorf("invalid --max-resolution %q, expected e.g. 720p or 1280x720", maxResolution)
+	}
+	height, _ := strconv.Atoi(m[1] + m[2])
+	if height < 1 {
+		return nil, fmt.Errorf("invalid --max-resolution %q, must be positive", maxResolution)
+	}
+	return &renditionSelection{maxHeight: height}, nil
+}
+
+// apply replaces the URL of every HLS VOD with the media playlist URL of the
+// selected rendition, keeping the master playlist URL alongside. URLs none of
+// whose renditions match are removed and recorded as errors of their session.
+func (s *renditionSelection) apply(ctx context.Context, results []sessionResult, summary *runSummary) {
+	for i := range results {
+		result := &results[i]
+		urls := result.URLs[:0]
+		for _, url := range result.URLs {
+			if url.Format != brightcove.ManifestFormatHLS {
+				urls = append(urls, url)
+				continue
+			}
+
+			mediaURL, name, err := s.selectRendition(ctx, url.URL)
+			if err != nil {
+				slog.WarnContext(ctx, "error selecting rendition", "session_id", result.SessionID, "err", err)
+				summary.addFailure("session "+result.SessionID+" ("+url.Format+")", err)
+				info := newErrorInfo(err)
+				info.Format = url.Format
+				result.Errors = append(result.Errors, *info)
+				continue
+			}
+			url.MasterURL, url.URL, url.Rendition = url.URL, mediaURL, name
+			urls = append(urls, url)
+		}
+		result.URLs = urls
+	}
+}
+
+// selectRendition fetches a master playlist and returns the media playlist URL
+// of the selected rendition and a description of it.
+func (s *renditionSelection) selectRendition(ctx context.Context, masterURL string) (string, string, error) {
+	body, err := fetchManifest(ctx, s.httpClient, masterURL)
+	if err != nil {
+		return "", "", err
+	}
+	if !hls.IsMaster(body) {
+		return "", "", errors.New("VOD URL is not a master playlist")
+	}
+	master, err := hls.ParseMaster(body, masterURL)
+	if err != nil {
+		return "", "", fmt.Errorf("error parsing master playlist: %w", err)
+	}
+
+	var mediaURL, name string
+	if s.audioOnly {
+		mediaURL, name = audioRendition(master)
+		if mediaURL == "" {
+			return "", "", fmt.Errorf("%w: master playlist has no audio-only rendition", errNoRendition)
+		}
+	} else {
+		var best *hls.Variant
+		for i, v := range master.Variants {
+			if height := variantHeight(v); height == 0 || height > s.maxHeight {
+				continue
+			}
+			if best == nil || v.Bandwidth > best.Bandwidth {
+				best = &master.Variants[i]
+			}
+		}
+		if best == nil {
+			return "", "", fmt.Errorf("%w: master playlist has no rendition of at most %dp", errNoRendition, s.maxHeight)
+		}
+		mediaURL = best.URI
+		name = rendition{Resolution: best.Resolution, Bandwidth: best.Bandwidth, Codecs: best.Codecs}.String()
+	}
+
+	return withMasterQuery(mediaURL, masterURL), name, nil
+}
+
+// audioRendition returns the media playlist URL and description of the audio
+// rendition of a master playlist: its default audio alternative, else any
+// other, else a variant without video.
+func audioRendition(master *hls.MasterPlaylist) (string, string) {
+	var audio []hls.Media
+	for _, m := range master.Media {
+		if m.Type == "AUDIO" && m.URI != "" {
+			audio = append(audio, m)
+		}
+	}
+	if len(audio) > 0 {
+		// Default renditions first, keeping the playlist's order otherwise
+		slices.SortStableFunc(audio, func(a, b hls.Media) int {
+			switch {
+			case a.Default == b.Default:
+				return 0
+			case a.Default:
+				return -1
+			}
+			return 1
+		})
+		name := "audio"
+		if audio[0].Name != "" {
+			name += " " + audio[0].Name
+		}
+		return audio[0].URI, name
+	}
+
+	for _, v := range master.Variants {
+		if v.Resolution == "" && !hasVideoCodec(v.Codecs) && v.Codecs != "" {
+			return v.URI, "audio@" + strconv.Itoa(v.Bandwidth/1000) + "kbps"
+		}
+	}
+	return "", ""
+}
+
+// variantHeight returns the height of a variant's RESOLUTION, 0 if it has
+// none.
+func variantHeight(v hls.Variant) int {
+	_, height, ok := strings.Cut(v.Resolution, "x")
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.Atoi(height)
+	return n
+}
+
+// hasVideoCodec reports whether a CODECS attribute lists a video codec.
+func hasVideoCodec(codecs string) bool {
+	for _, codec := range strings.Split(codecs, ",") {
+		codec = strings.TrimSpace(codec)
+		for _, prefix := range []string{"avc", "hvc", "hev", "vp0", "vp9", "av01", "dvh"} {
+			if strings.HasPrefix(codec, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withMasterQuery adds the query parameters of the master playlist URL, e.g.
+// a playback authorization, that the media playlist URL lacks, as relative
+// URIs don't inherit them.
+func withMasterQuery(mediaURL, masterURL string) string {
+	media, err := neturl.Parse(mediaURL)
+	if err != nil {
+		return mediaURL
+	}
+	master, err := neturl.Parse(masterURL)
+	if err != nil || master.RawQuery == "" {
+		return mediaURL
+	}
+
+	query := media.Query()
+	added := false
+	for key, values := range master.Query() {
+		if !query.Has(key) {
+			query[key] = values
+			added = true
+		}
+	}
+	if !added {
+		return mediaURL
+	}
+	media.RawQuery = query.Encode()
+	return media.String()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// errNoSuchSession means a selected session ID or index isn't one of the
+// resource's sessions.
+var errNoSuchSession = errors.New("no such session")
+
+// sessionSelection narrows down which sessions of a resource get VOD URLs.
+// The zero value selects every session.
+type sessionSelection struct {
+	ids   []string
+	index int
+	// latest and oldest keep only the N most recent or earliest ended
+	// sessions of the ones selected otherwise.
+	latest int
+	oldest int
+}
+
+func (sel sessionSelection) empty() bool {
+	return len(sel.ids) == 0 && sel.index < 0 && sel.latest == 0 && sel.oldest == 0
+}
+
+func (sel sessionSelection) validate() error {
+	if sel.index < -1 {
+		return fmt.Errorf("session index %d must not be negative", sel.index)
+	}
+	if sel.latest < 0 || sel.oldest < 0 {
+		return errors.New("--latest and --oldest must not be negative")
+	}
+	if sel.latest > 0 && sel.oldest > 0 {
+		return errors.New("expected either --latest or --oldest, not both")
+	}
+	return nil
+}
+
+// apply returns the selected sessions, oldest first as listed by the client.
+// Indexes refer to the position of a session in that list.
+func (sel sessionSelection) apply(sessions *brightcove.Sessions) (*brightcove.Sessions, error) {
+	if sel.empty() {
+		return sessions, nil
+	}
+	if len(sel.ids) == 0 && sel.index < 0 {
+		return sel.limit(sessions), nil
+	}
+
+	if sel.index >= len(sessions.Events) {
+		return nil, fmt.Errorf("%w: session index %d out of range, resource has %d sessions", errNoSuchSession, sel.index, len(sessions.Events))
+	}
+
+	for _, id := range sel.ids {
+		if !slices.ContainsFunc(sessions.Events, func(s brightcove.Session) bool { return s.ID == id }) {
+			return nil, fmt.Errorf("%w: session %s not found", errNoSuchSession, id)
+		}
+	}
+
+	selected := &brightcove.Sessions{}
+	for i, session := range sessions.Events {
+		if i == sel.index || slices.Contains(sel.ids, session.ID) {
+			selected.Events = append(selected.Events, session)
+		}
+	}
+
+	return sel.limit(selected), nil
+}
+
+// limit applies --latest and --oldest. Live sessions don't count towards
+// either and are kept, so the live check still sees them.
+func (sel sessionSelection) limit(sessions *brightcove.Sessions) *brightcove.Sessions {
+	n := max(sel.latest, sel.oldest)
+	if n == 0 {
+		return sessions
+	}
+
+	var live, ended []brightcove.Session
+	for _, session := range sessions.Events {
+		if session.EndTime == 0 {
+			live = append(live, session)
+		} else {
+			ended = append(ended, session)
+		}
+	}
+
+	n = min(n, len(ended))
+	if sel.latest > 0 {
+		ended = ended[len(ended)-n:]
+	} else {
+		ended = ended[:n]
+	}
+
+	return &brightcove.Sessions{Events: append(ended, live...)}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+func TestSessionSelection(t *testing.T) {
+	sessions := &brightcove.Sessions{Events: []brightcove.Session{
+		{ID: "a", StartTime: 1_700_000_000, EndTime: 1_700_003_600},
+		{ID: "b", StartTime: 1_700_010_000, EndTime: 1_700_013_600},
+		{ID: "c", StartTime: 1_700_020_000, EndTime: 1_700_023_600},
+		{ID: "live", StartTime: 1_700_030_000},
+	}}
+
+	tests := []struct {
+		name    string
+		sel     sessionSelection
+		want    []string
+		wantErr bool
+	}{
+		{name: "every session", sel: sessionSelection{index: -1}, want: []string{"a", "b", "c", "live"}},
+		{name: "index", sel: sessionSelection{index: 1}, want: []string{"b"}},
+		{name: "ids in listed order", sel: sessionSelection{ids: []string{"c", "a"}, index: -1}, want: []string{"a", "c"}},
+		{name: "index and id", sel: sessionSelection{ids: []string{"c"}, index: 0}, want: []string{"a", "c"}},
+		{name: "latest", sel: sessionSelection{index: -1, latest: 2}, want: []string{"b", "c", "live"}},
+		{name: "oldest", sel: sessionSelection{index: -1, oldest: 1}, want: []string{"a", "live"}},
+		{name: "more than ended", sel: sessionSelection{index: -1, latest: 5}, want: []string{"a", "b", "c", "live"}},
+		{name: "latest of ids", sel: sessionSelection{ids: []string{"a", "b"}, index: -1, latest: 1}, want: []string{"b"}},
+		{name: "index out of range", sel: sessionSelection{index: 4}, wantErr: true},
+		{name: "unknown id", sel: sessionSelection{ids: []string{"a", "d"}, index: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := tt.sel.apply(sessions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var ids []string
+			for _, session := range selected.Events {
+				ids = append(ids, session.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("got sessions %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/history"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// vodURLsRequest is the body of POST /v1/vod-urls. Either PlaybackURL or both
+// AccountID and ResourceID identify the resource.
+type vodURLsRequest struct {
+	PlaybackURL  string   `json:"playback_url"`
+	AccountID    string   `json:"account_id"`
+	ResourceID   string   `json:"resource_id"`
+	Format       string   `json:"format"`
+	SessionIDs   []string `json:"session_ids"`
+	SessionIndex *int     `json:"session_index"`
+}
+
+type vodURLsResponse struct {
+	AccountID  string          `json:"account_id"`
+	ResourceID string          `json:"resource_id"`
+	Sessions   []sessionResult `json:"sessions"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type statusResponse struct {
+	Status string `json:"status"`
+}
+
+// server exposes VOD generation over HTTP.
+type server struct {
+	client         *brightcove.Client
+	requestTimeout time.Duration
+	history        *history.Store
+	audit          *auditLog
+	notifiers      []notifier
+	metrics        *metrics
+
+	// draining is set once the server is shutting down, failing /readyz.
+	draining atomic.Bool
+	// background tracks the notifications still being sent.
+	background sync.WaitGroup
+	// grpcHealth, when the gRPC API is served, is its health service.
+	grpcHealth *health.Server
+}
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("vodurls serve", flag.ExitOnError)
+	setUsage(fs, "Serves VOD URL generation over HTTP and optionally gRPC.",
+		"./vodurls serve [--addr :8080] [--grpc-addr :9090] [--db PATH]",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	var af auditFlags
+	af.register(fs)
+	var nf notifyFlags
+	nf.register(fs)
+	addr := fs.String("addr", envString("LISTEN_ADDR", ":8080"), "address to listen on (env LISTEN_ADDR)")
+	grpcAddr := fs.String("grpc-addr", envString("GRPC_LISTEN_ADDR", ""), "also serve the gRPC API on this address, e.g. :9090 (env GRPC_LISTEN_ADDR)")
+	sessionCacheTTL := fs.Duration("session-cache-ttl", envDuration("SESSION_CACHE_TTL", time.Minute), "reuse the sessions of a resource fetched within this long across the requests served (env SESSION_CACHE_TTL)")
+	noSessionCache := fs.Bool("no-session-cache", false, "fetch the sessions of a resource on every request served")
+	shutdownDelay := fs.Duration("shutdown-delay", envDuration("SHUTDOWN_DELAY", 0), "on SIGTERM, keep accepting requests this long with /readyz failing, so load balancers stop routing to the server first (env SHUTDOWN_DELAY)")
+	shutdownTimeout := fs.Duration("shutdown-timeout", envDuration("SHUTDOWN_TIMEOUT", defaultShutdownTimeout), "on SIGTERM, how long in-flight requests get to complete before they are cancelled (env SHUTDOWN_TIMEOUT)")
+	db := fs.String("db", envString("HISTORY_DB", ""), "record generated URLs in this SQLite history database (env HISTORY_DB)")
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if *sessionCacheTTL < 0 {
+		slog.Error("session cache TTL must not be negative", "session_cache_ttl", *sessionCacheTTL)
+		exit(1)
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	if !*noSessionCache && *sessionCacheTTL > 0 {
+		client.SessionCache = brightcove.NewSessionCache(*sessionCacheTTL)
+	}
+
+	store, err := openHistory(*db)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	if store != nil {
+		defer store.Close()
+	}
+
+	notifiers, err := nf.notifiers(&cf)
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	auditLog, err := af.open()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+	if auditLog != nil {
+		defer auditLog.Close()
+	}
+
+	// --timeout bounds each request instead of the lifetime of the server
+	srv := &server{client: client, requestTimeout: cf.timeout, history: store, audit: auditLog, metrics: newMetrics(), notifiers: notifiers}
+	client.Observer = srv.metrics
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/vod-urls", srv.handleVODURLs)
+	mux.Handle("GET /metrics", srv.metrics.handler())
+	mux.HandleFunc("GET /healthz", srv.handleHealthz)
+	mux.HandleFunc("GET /readyz", srv.handleReadyz)
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	var grpcServer *grpc.Server
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			slog.Error("error listening for gRPC", "err", err)
+			exit(1)
+		}
+		grpcServer = newGRPCServer(srv)
+		go func() {
+			slog.Info("listening for gRPC", "addr", *grpcAddr)
+			if err := grpcServer.Serve(lis); err != nil {
+				slog.Error("error running gRPC server", "err", err)
+				exit(1)
+			}
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", *addr)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		slog.Error("error running server", "err", err)
+		exit(1)
+	case <-ctx.Done():
+	}
+
+	srv.drain(httpServer, grpcServer, *shutdownDelay, *shutdownTimeout)
+}
+
+// drain shuts the server down: /readyz fails right away, requests are still
+// accepted for delay so load balancers stop routing to the server, then
+// in-flight requests and their notifications get up to timeout to complete
+// before they are cancelled.
+func (s *server) drain(httpServer *http.Server, grpcServer *grpc.Server, delay, timeout time.Duration) {
+	s.draining.Store(true)
+	if s.grpcHealth != nil {
+		s.grpcHealth.Shutdown()
+	}
+	slog.Info("shutting down, draining in-flight requests", "delay", delay, "timeout", timeout)
+	time.Sleep(delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		if grpcServer == nil {
+			return
+		}
+		graceful := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(graceful)
+		}()
+		select {
+		case <-graceful:
+		case <-ctx.Done():
+			grpcServer.Stop()
+		}
+	}()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		slog.Error("error draining requests, cancelling them", "err", err)
+		httpServer.Close()
+	}
+	<-grpcStopped
+
+	notified := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(notified)
+	}()
+	select {
+	case <-notified:
+		slog.Info("shut down")
+	case <-ctx.Done():
+		slog.Warn("abandoning pending notifications")
+	}
+}
+
+// handleHealthz reports that the process is alive.
+func (s *server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(w, http.StatusOK, statusResponse{Status: "ok"})
+}
+
+// handleReadyz reports whether the server can take requests: it isn't
+// shutting down and holds, or can get, an access token.
+func (s *server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		writeJSONResponse(w, http.StatusServiceUnavailable, errorResponse{Error: "shutting down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	if _, err := s.client.AccessToken(ctx); err != nil {
+		writeJSONResponse(w, http.StatusServiceUnavailable, errorResponse{Error: "error getting access token: " + err.Error()})
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, statusResponse{Status: "ready"})
+}
+
+func (s *server) handleVODURLs(w http.ResponseWriter, r *http.Request) {
+	var req vodURLsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeJSONResponse(w, http.StatusBadRequest, errorResponse{Error: "error decoding body: " + err.Error()})
+		return
+	}
+
+	resp, err := s.generateVODURLs(r.Context(), req)
+	if err != nil {
+		writeJSONResponse(w, errorStatus(err), errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// errorStatus returns the HTTP status of an error of generateVODURLs: a 4xx
+// for requests that can't be served as sent or resources that have no VOD to
+// serve, a 502 for failures of the Brightcove APIs.
+func errorStatus(err error) int {
+	var badRequest *badRequestError
+	switch {
+	case errors.As(err, &badRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, brightcove.ErrResourceNotFound), errors.Is(err, errNoSuchSession):
+		return http.StatusNotFound
+	case errors.Is(err, brightcove.ErrLiveSessionActive):
+		return http.StatusConflict
+	case errors.Is(err, brightcove.ErrVODWindowExpired), errors.Is(err, brightcove.ErrNoSessions):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// badRequestError is the error of a request that can't be served as sent.
+type badRequestError struct {
+	err error
+}
+
+func (e *badRequestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *badRequestError) Unwrap() error {
+	return e.err
+}
+
+// generateVODURLs serves a single request, of the REST or the gRPC API. It
+// returns a *badRequestError for requests that can't be served as sent.
+func (s *server) generateVODURLs(ctx context.Context, req vodURLsRequest) (*vodURLsResponse, error) {
+	accountID, resourceID := req.AccountID, req.ResourceID
+	if req.PlaybackURL != "" {
+		var err error
+		accountID, resourceID, err = s.client.ResolvePlaybackURL(req.PlaybackURL)
+		if err != nil {
+			return nil, &badRequestError{err}
+		}
+	}
+	if accountID == "" || resourceID == "" {
+		return nil, &badRequestError{errors.New("playback_url or account_id and resource_id are required")}
+	}
+
+	formats, err := parseFormat(req.Format)
+	if err != nil {
+		return nil, &badRequestError{err}
+	}
+	formats = inferFormats(formats, req.PlaybackURL)
+
+	opts := generateOptions{
+		formats:   formats,
+		selection: sessionSelection{ids: req.SessionIDs, index: -1},
+		history:   s.history,
+		audit:     s.audit,
+	}
+	if req.SessionIndex != nil {
+		if *req.SessionIndex < 0 {
+			return nil, &badRequestError{fmt.Errorf("session_index %d must not be negative", *req.SessionIndex)}
+		}
+		opts.selection.index = *req.SessionIndex
+	}
+	if err := opts.selection.validate(); err != nil {
+		return nil, &badRequestError{err}
+	}
+
+	if s.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.requestTimeout)
+		defer cancel()
+	}
+
+	results, err := generate(ctx, s.client, accountID, resourceID, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "error generating VOD URLs", "account_id", accountID, "resource_id", resourceID, "err", err)
+		return nil, err
+	}
+
+	s.metrics.addResults(results)
+	if len(s.notifiers) > 0 {
+		// Don't hold the response back, nor cancel the notifications with it
+		s.background.Add(1)
+		go func() {
+			defer s.background.Done()
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer cancel()
+			notifyAll(ctx, s.notifiers, singleResult(accountID, resourceID, results))
+		}()
+	}
+
+	return &vodURLsResponse{
+		AccountID:  accountID,
+		ResourceID: resourceID,
+		Sessions:   results,
+	}, nil
+}
+
+func writeJSONResponse(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("error writing response", "err", err)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcovetest"
+)
+
+func TestServeStatus(t *testing.T) {
+	srv := brightcovetest.NewServer()
+	defer srv.Close()
+	now := int(time.Now().Unix())
+	srv.AddSession(brightcove.Session{ID: "1", AccountID: testAccountID, ResourceID: "100", StartTime: now - 7200, EndTime: now - 3600})
+	srv.AddSession(brightcove.Session{ID: "2", AccountID: testAccountID, ResourceID: "101", StartTime: now - 600})
+	srv.AddSession(brightcove.Session{ID: "3", AccountID: testAccountID, ResourceID: "102", StartTime: now - 30*86400, EndTime: now - 29*86400})
+
+	s := &server{
+		client:  srv.NewClient(brightcove.WithRetryPolicy(brightcove.RetryPolicy{MaxAttempts: 1})),
+		metrics: newMetrics(),
+	}
+
+	tests := []struct {
+		name string
+		body string
+		// fail makes the playback token call fail.
+		fail bool
+		want int
+	}{
+		{name: "generated", body: `{"account_id":"200","resource_id":"100"}`, want: http.StatusOK},
+		{name: "selected session", body: `{"account_id":"200","resource_id":"100","session_index":0}`, want: http.StatusOK},
+		{name: "invalid body", body: `{`, want: http.StatusBadRequest},
+		{name: "missing resource", body: `{"account_id":"200"}`, want: http.StatusBadRequest},
+		{name: "negative session index", body: `{"account_id":"200","resource_id":"100","session_index":-5}`, want: http.StatusBadRequest},
+		{name: "unknown format", body: `{"account_id":"200","resource_id":"100","format":"mp4"}`, want: http.StatusBadRequest},
+		{name: "session index out of range", body: `{"account_id":"200","resource_id":"100","session_index":3}`, want: http.StatusNotFound},
+		{name: "unknown session", body: `{"account_id":"200","resource_id":"100","session_ids":["9"]}`, want: http.StatusNotFound},
+		{name: "live", body: `{"account_id":"200","resource_id":"101"}`, want: http.StatusConflict},
+		{name: "expired", body: `{"account_id":"200","resource_id":"102"}`, want: http.StatusUnprocessableEntity},
+		{name: "no sessions", body: `{"account_id":"200","resource_id":"999"}`, want: http.StatusUnprocessableEntity},
+		{name: "Brightcove failure", body: `{"account_id":"200","resource_id":"100"}`, fail: true, want: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.fail {
+				srv.Fail(brightcovetest.EndpointPlaybackToken, http.StatusServiceUnavailable, "", 1)
+			}
+			rec := httptest.NewRecorder()
+			s.handleVODURLs(rec, httptest.NewRequest(http.MethodPost, "/v1/vod-urls", strings.NewReader(tt.body)))
+			if rec.Code != tt.want {
+				t.Errorf("got status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// sessionInfo is the output record of the sessions command.
+type sessionInfo struct {
+	Index           int    `json:"index"`
+	ID              string `json:"id"`
+	ResourceID      string `json:"resource_id"`
+	AccountID       string `json:"account_id"`
+	StartTime       int    `json:"start_time"`
+	EndTime         int    `json:"end_time"`
+	DurationSeconds int    `json:"duration_seconds"`
+	Live            bool   `json:"live"`
+	InVODWindow     bool   `json:"in_vod_window"`
+	// VODExpiresAt is when an ended session falls out of the VOD window, and
+	// VODRemainingSeconds how long is left until then, 0 once it did.
+	VODExpiresAt        time.Time `json:"vod_expires_at,omitzero"`
+	VODRemainingSeconds int       `json:"vod_remaining_seconds"`
+}
+
+func runSessions(args []string) {
+	fs := flag.NewFlagSet("vodurls sessions", flag.ExitOnError)
+	setUsage(fs, "Lists the sessions of a live resource and whether VOD URLs can be generated for them.",
+		"./vodurls sessions [--output text|json] <PLAYBACK_URL>",
+		"./vodurls sessions [--output text|json] --account ID --resource ID",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	var rf resourceFlags
+	rf.register(fs)
+	output := fs.String("output", outputText, "output format (text or json)")
+	registerTZ(fs)
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() == 0 && !rf.set() {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if *output != outputText && *output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", *output)
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	accountID, resourceID, err := rf.resolve(ctx, client, cf.profile.AccountID, fs.Arg(0))
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	sessions, err := client.GetResourceSessions(ctx, accountID, resourceID)
+	if err != nil {
+		slog.Error("error getting sessions", "err", err)
+		exit(1)
+	}
+
+	infos := describeSessions(sessions, cf.vodWindowDays)
+
+	if *output == outputJSON {
+		err = writeJSON(os.Stdout, infos)
+	} else {
+		err = writeSessionsTable(os.Stdout, infos)
+	}
+	if err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+}
+
+func describeSessions(sessions *brightcove.Sessions, vodWindowDays int) []sessionInfo {
+	infos := make([]sessionInfo, 0, len(sessions.Events))
+
+	for i, session := range sessions.Events {
+		info := sessionInfo{
+			Index:      i,
+			ID:         session.ID,
+			ResourceID: session.ResourceID,
+			AccountID:  session.AccountID,
+			StartTime:  session.StartTime,
+			EndTime:    session.EndTime,
+			Live:       session.EndTime == 0,
+		}
+
+		// A live session's duration is how long it has been streaming so far
+		if info.Live {
+			info.DurationSeconds = int(time.Now().Unix()) - session.StartTime
+		} else {
+			info.DurationSeconds = session.EndTime - session.StartTime
+			info.InVODWindow = session.WithinVODWindow(vodWindowDays)
+			info.VODExpiresAt = session.VODExpiry(vodWindowDays)
+			info.VODRemainingSeconds = max(int(time.Until(info.VODExpiresAt).Seconds()), 0)
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+func writeSessionsTable(w io.Writer, infos []sessionInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "INDEX\tSESSION ID\tSTART\tEND\tDURATION\tVOD")
+
+	for _, info := range infos {
+		end, vod := "-", "live"
+		if !info.Live {
+			end = formatEpoch(info.EndTime)
+			vod = "eligible, " + formatDays(time.Duration(info.VODRemainingSeconds)*time.Second) + " left"
+			if !info.InVODWindow {
+				vod = "expired"
+			}
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			info.Index,
+			info.ID,
+			formatEpoch(info.StartTime),
+			end,
+			formatDuration(info.DurationSeconds),
+			vod,
+		)
+	}
+
+	return tw.Flush()
+}
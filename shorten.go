@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/shortlink"
+)
+
+// shortenResults sets the short link of every URL. A URL that can't be
+// shortened is still printed, without a short link.
+func shortenResults(ctx context.Context, shortener shortlink.Shortener, results []sessionResult) {
+	for i := range results {
+		for j := range results[i].URLs {
+			url := &results[i].URLs[j]
+
+			short, err := shortener.Shorten(ctx, url.URL)
+			if err != nil {
+				slog.WarnContext(ctx, "error shortening VOD URL", "session_id", results[i].SessionID, "format", url.Format, "err", err)
+				continue
+			}
+			url.ShortURL = short
+		}
+	}
+}
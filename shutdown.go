@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout is how long serve and refresh let in-flight work
+// finish once asked to stop.
+const defaultShutdownTimeout = 30 * time.Second
+
+// shutdownSignals end a run: SIGINT from the terminal and SIGTERM from
+// process managers such as Kubernetes.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// signalContext returns a context cancelled on the first shutdown signal.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), shutdownSignals...)
+}
+
+// drainContext returns a context that outlives ctx by grace, so work started
+// before ctx was done can finish: it is cancelled grace after ctx is.
+func drainContext(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-drainCtx.Done():
+			return
+		}
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel()
+		case <-drainCtx.Done():
+		}
+	}()
+	return drainCtx, cancel
+}
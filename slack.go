@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// slackNotifier posts the generated VOD URLs to a Slack incoming webhook.
+type slackNotifier struct {
+	webhook    string
+	httpClient *http.Client
+}
+
+func (n *slackNotifier) notify(ctx context.Context, batch batchResult) error {
+	message := struct {
+		Text string `json:"text"`
+	}{Text: slackMessage(batch)}
+
+	if err := postJSON(ctx, n.httpClient, n.webhook, message, nil); err != nil {
+		return fmt.Errorf("error notifying Slack: %w", err)
+	}
+	return nil
+}
+
+// slackMessage formats batch as Slack mrkdwn: a section per resource with a
+// line per session linking its URLs, which are far too long to print.
+func slackMessage(batch batchResult) string {
+	var b strings.Builder
+	var urls, failed int
+	for _, result := range batch {
+		for _, session := range result.Sessions {
+			urls += len(session.URLs)
+		}
+		if result.Error != "" {
+			failed++
+		}
+	}
+	fmt.Fprintf(&b, "*%d VOD URLs generated*", urls)
+	if failed > 0 {
+		fmt.Fprintf(&b, ", %d playback URLs failed", failed)
+	}
+	b.WriteString("\n")
+
+	for _, result := range batch {
+		resource := result.ResourceID
+		if resource == "" {
+			resource = result.PlaybackURL
+		}
+		fmt.Fprintf(&b, "\n*Resource %s*\n", slackEscape(resource))
+		if result.Error != "" {
+			fmt.Fprintf(&b, "FAILED: %s\n", slackEscape(result.Error))
+			continue
+		}
+
+		for _, session := range result.Sessions {
+			var links []string
+			for _, url := range session.URLs {
+				link := url.URL
+				if url.ShortURL != "" {
+					link = url.ShortURL
+				}
+				links = append(links, fmt.Sprintf("<%s|%s>", link, strings.ToUpper(url.Format)))
+			}
+			fmt.Fprintf(&b, "• %s – %s: %s\n", slackTime(session.StartTime), slackTime(session.EndTime), strings.Join(links, " "))
+		}
+	}
+	return b.String()
+}
+
+func slackTime(epoch int) string {
+	return time.Unix(int64(epoch), 0).UTC().Format("2006-01-02 15:04 MST")
+}
+
+// slackEscape escapes the characters Slack gives a meaning in message text.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// dayLayout renders the days of the stats histogram.
+const dayLayout = "2006-01-02"
+
+// histogramWidth is the length of the longest bar of the stats histogram.
+const histogramWidth = 40
+
+// resourceStats is the output record of the stats command.
+type resourceStats struct {
+	AccountID    string `json:"account_id"`
+	ResourceID   string `json:"resource_id"`
+	Sessions     int    `json:"sessions"`
+	LiveSessions int    `json:"live_sessions"`
+	// Live sessions count with how long they have been streaming so far.
+	TotalDurationSeconds   int     `json:"total_duration_seconds"`
+	TotalHours             float64 `json:"total_hours"`
+	AverageDurationSeconds int     `json:"average_duration_seconds"`
+	InVODWindow            int     `json:"in_vod_window"`
+	OutsideVODWindow       int     `json:"outside_vod_window"`
+	// Days holds every day from the first session to the last one, by the
+	// day sessions started on.
+	Days []dayStats `json:"days"`
+}
+
+type dayStats struct {
+	Date     string  `json:"date"`
+	Sessions int     `json:"sessions"`
+	Hours    float64 `json:"hours"`
+}
+
+func runStats(args []string) {
+	fs := flag.NewFlagSet("vodurls stats", flag.ExitOnError)
+	setUsage(fs, "Reports how many sessions a live resource streamed, for how long and how many are still inside the VOD window, with a histogram per day.",
+		"./vodurls stats [--since 30d] [--output text|json] <PLAYBACK_URL>",
+		"./vodurls stats [--since 30d] [--output text|json] --account ID --resource ID",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	var rf resourceFlags
+	rf.register(fs)
+	var since dayDuration
+	fs.Var(&since, "since", "only count sessions started within this long, e.g. 30d, all of them by default")
+	output := fs.String("output", outputText, "output format (text or json)")
+	registerTZ(fs)
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() == 0 && !rf.set() {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if *output != outputText && *output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", *output)
+		exit(1)
+	}
+
+	if since < 0 {
+		slog.Error("--since must not be negative", "since", since.String())
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	accountID, resourceID, err := rf.resolve(ctx, client, cf.profile.AccountID, fs.Arg(0))
+	if err != nil {
+		slog.Error(err.Error())
+		exit(exitCode(err))
+	}
+
+	filter := brightcove.SessionFilter{Max: client.MaxSessions}
+	if since > 0 {
+		filter.StartedAfter = time.Now().Add(-time.Duration(since))
+	}
+	sessions, err := client.ListResourceSessions(ctx, accountID, resourceID, filter)
+	if err != nil {
+		slog.Error("error getting sessions", "err", err)
+		exit(exitCode(err))
+	}
+
+	stats := sessionStats(accountID, resourceID, describeSessions(sessions, cf.vodWindowDays))
+
+	if *output == outputJSON {
+		err = writeJSON(os.Stdout, stats)
+	} else {
+		err = writeStats(os.Stdout, stats)
+	}
+	if err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+}
+
+// sessionStats aggregates the sessions of a resource, sorted by start time.
+func sessionStats(accountID, resourceID string, infos []sessionInfo) resourceStats {
+	stats := resourceStats{AccountID: accountID, ResourceID: resourceID, Sessions: len(infos), Days: []dayStats{}}
+	if len(infos) == 0 {
+		return stats
+	}
+
+	perDay := map[string]*dayStats{}
+	for _, info := range infos {
+		stats.TotalDurationSeconds += info.DurationSeconds
+		switch {
+		case info.Live:
+			stats.LiveSessions++
+		case info.InVODWindow:
+			stats.InVODWindow++
+		default:
+			stats.OutsideVODWindow++
+		}
+
+		date := startDay(info.StartTime).Format(dayLayout)
+		day, ok := perDay[date]
+		if !ok {
+			day = &dayStats{Date: date}
+			perDay[date] = day
+		}
+		day.Sessions++
+		day.Hours += float64(info.DurationSeconds) / 3600
+	}
+	stats.TotalHours = roundHours(float64(stats.TotalDurationSeconds) / 3600)
+	stats.AverageDurationSeconds = stats.TotalDurationSeconds / len(infos)
+
+	// Days without sessions are part of the histogram too
+	last := startDay(infos[len(infos)-1].StartTime)
+	for day := startDay(infos[0].StartTime); !day.After(last); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dayLayout)
+		entry := dayStats{Date: date}
+		if counted, ok := perDay[date]; ok {
+			entry = *counted
+			entry.Hours = roundHours(entry.Hours)
+		}
+		stats.Days = append(stats.Days, entry)
+	}
+	return stats
+}
+
+// startDay returns the midnight starting the day of epoch in the time zone of
+// text output.
+func startDay(epoch int) time.Time {
+	y, m, d := time.Unix(int64(epoch), 0).In(displayLocation).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, displayLocation)
+}
+
+// roundHours rounds hours to 2 decimals for output.
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
+
+func writeStats(w io.Writer, stats resourceStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Resource:\t%s of account %s\n", stats.ResourceID, stats.AccountID)
+	fmt.Fprintf(tw, "Sessions:\t%d (%d live)\n", stats.Sessions, stats.LiveSessions)
+	fmt.Fprintf(tw, "Streamed:\t%s\n", formatDuration(stats.TotalDurationSeconds))
+	fmt.Fprintf(tw, "Average length:\t%s\n", formatDuration(stats.AverageDurationSeconds))
+	fmt.Fprintf(tw, "In VOD window:\t%d\n", stats.InVODWindow)
+	fmt.Fprintf(tw, "Outside VOD window:\t%d\n", stats.OutsideVODWindow)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(stats.Days) == 0 {
+		return nil
+	}
+
+	most := 0
+	for _, day := range stats.Days {
+		most = max(most, day.Sessions)
+	}
+
+	fmt.Fprintf(w, "\n%-10s  %-8s  %-5s\n", "DAY", "SESSIONS", "HOURS")
+	for _, day := range stats.Days {
+		// Every day with sessions gets a bar, however short
+		bar := strings.Repeat("#", (day.Sessions*histogramWidth+most-1)/most)
+		line := fmt.Sprintf("%-10s  %-8d  %-5.2f  %s", day.Date, day.Sessions, day.Hours, bar)
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// runSummary tallies the outcome of a run for the summary logged at its end.
+// A nil summary records nothing.
+type runSummary struct {
+	mu        sync.Mutex
+	generated int
+	skipped   int
+	failures  []failure
+}
+
+// failure is an item of the run that failed and why.
+type failure struct {
+	item   string
+	reason string
+}
+
+func (s *runSummary) addGenerated(n int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generated += n
+}
+
+func (s *runSummary) addSkipped(n int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skipped += n
+}
+
+// addFailure records a failed item. The sessions of a *brightcove.PartialError
+// are recorded one by one.
+func (s *runSummary) addFailure(item string, err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var partial *brightcove.PartialError
+	if errors.As(err, &partial) {
+		for _, sessionErr := range partial.Errors {
+			s.failures = append(s.failures, failure{item: "session " + sessionErr.Session.ID + " (" + sessionErr.Format + ")", reason: sessionErr.Err.Error()})
+		}
+		return
+	}
+	s.failures = append(s.failures, failure{item: item, reason: err.Error()})
+}
+
+func (s *runSummary) failed() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.failures)
+}
+
+// log writes the summary to the diagnostics.
+func (s *runSummary) log(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.failures {
+		slog.WarnContext(ctx, "failed", "item", f.item, "reason", f.reason)
+	}
+	slog.InfoContext(ctx, "summary", "generated", s.generated, "skipped", s.skipped, "failed", len(s.failures))
+}
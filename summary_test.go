@@ -0,0 +1,18 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNilRunSummary(t *testing.T) {
+	var s *runSummary
+	s.addGenerated(1)
+	s.addSkipped(1)
+	s.addFailure("session 1", errors.New("failed"))
+	if n := s.failed(); n != 0 {
+		t.Errorf("got %d failures, want 0", n)
+	}
+	s.log(context.Background())
+}
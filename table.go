@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+)
+
+// Statuses of the rows of table output.
+const (
+	statusGenerated      = "generated"
+	statusSkippedExpired = "skipped-expired"
+	statusFailed         = "failed"
+)
+
+// statusColors are the ANSI colors of the statuses on a terminal.
+var statusColors = map[string]string{
+	statusGenerated:      "\x1b[32m",
+	statusSkippedExpired: "\x1b[33m",
+	statusFailed:         "\x1b[31m",
+}
+
+// colorReset ends a status color. The header is framed with headerColor and
+// colorReset too, so every cell of the status column carries escape
+// sequences of the same length and tabwriter keeps it aligned.
+const (
+	colorReset  = "\x1b[0m"
+	headerColor = "\x1b[39m"
+)
+
+// cellReplacer turns the characters that would break the lines or columns of
+// a table, e.g. in the HTML body of a failed API call, into spaces.
+var cellReplacer = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ", "\t", " ")
+
+// tableRow is a line of table output: a generated URL, a URL that failed, or
+// a session skipped as outside the VOD window.
+type tableRow struct {
+	status     string
+	resourceID string
+	sessionID  string
+	start      string
+	duration   string
+	format     string
+	url        string
+	note       string
+}
+
+// sessionRows returns the rows of the URLs, failures and skipped sessions of
+// results.
+func sessionRows(results []sessionResult) []tableRow {
+	var rows []tableRow
+	for _, result := range results {
+		row := tableRow{
+			resourceID: result.ResourceID,
+			sessionID:  result.SessionID,
+			start:      "-",
+			duration:   "-",
+			format:     "-",
+			url:        "-",
+		}
+		if result.StartTime != 0 {
+			row.start = formatEpoch(result.StartTime)
+			row.duration = formatDuration(result.EndTime - result.StartTime)
+		}
+
+		if result.expired {
+			row.status = statusSkippedExpired
+			row.note = "VOD window ended " + formatTime(result.VODExpiresAt)
+			rows = append(rows, row)
+			continue
+		}
+		for _, url := range result.URLs {
+			row := row
+			row.status, row.format, row.url = statusGenerated, url.Format, url.URL
+			row.note = "expires " + formatTime(url.ExpiresAt)
+			if url.ExpiresAt.IsZero() {
+				row.note = ""
+			}
+			if url.Verified != nil && !*url.Verified {
+				row.status, row.note = statusFailed, "unverified: "+url.VerifyError
+			}
+			rows = append(rows, row)
+		}
+		for _, failure := range result.Errors {
+			row := row
+			row.status, row.format, row.note = statusFailed, failure.Format, failure.Message
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// batchRows returns the rows of every input of a batch, a failed input being
+// a single failed row.
+func batchRows(batch batchResult) []tableRow {
+	var rows []tableRow
+	for _, result := range batch {
+		// A failed input only holds sessions when all of them were expired
+		rows = append(rows, sessionRows(result.Sessions)...)
+		if result.Error == "" {
+			continue
+		}
+		rows = append(rows, tableRow{
+			status:     statusFailed,
+			resourceID: cmp.Or(result.ResourceID, "-"),
+			sessionID:  "-",
+			start:      "-",
+			duration:   "-",
+			format:     "-",
+			url:        cmp.Or(result.PlaybackURL, "-"),
+			note:       result.Error,
+		})
+	}
+	return rows
+}
+
+// writeTable prints rows as an aligned table, their status colored when w is
+// a terminal that accepts colors.
+func writeTable(w io.Writer, rows []tableRow) error {
+	color := useColor(w)
+	status := func(s, c string) string {
+		if !color {
+			return s
+		}
+		return c + s + colorReset
+	}
+
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tRESOURCE ID\tSESSION ID\tSTART\tDURATION\tFORMAT\tURL\tNOTE\n", status("STATUS", headerColor))
+	for _, row := range rows {
+		cells := []string{row.resourceID, row.sessionID, row.start, row.duration, strings.ToUpper(row.format), row.url, row.note}
+		for i, cell := range cells {
+			cells[i] = cellReplacer.Replace(cell)
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", status(row.status, statusColors[row.status]), strings.Join(cells, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// Empty notes leave the padding of the URL column behind
+	for line := range strings.Lines(buf.String()) {
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " \n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// useColor reports whether output to w is colored: only on a terminal, and
+// neither with NO_COLOR set (https://no-color.org) nor on a dumb terminal.
+func useColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/hls"
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// thumbnailOffset is how far into a VOD its thumbnail is grabbed, past the
+// black frames recordings often start with.
+const thumbnailOffset = 10 * time.Second
+
+// writeThumbnails grabs a frame near the start of every session's VOD with
+// ffmpeg and writes it to dir as <resource>-<session>.jpg, preferring the
+// HLS URL of a session as only the segment holding the frame is downloaded.
+// A thumbnail that can't be grabbed is logged and left out, the URLs are
+// usable without it.
+func writeThumbnails(ctx context.Context, httpClient *http.Client, dir string, results []sessionResult) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating thumbnail directory: %w", err)
+	}
+
+	for i := range results {
+		result := &results[i]
+		if len(result.URLs) == 0 {
+			continue
+		}
+		url := result.URLs[0]
+		if j := slices.IndexFunc(result.URLs, func(u resultURL) bool { return u.Format == brightcove.ManifestFormatHLS }); j != -1 {
+			url = result.URLs[j]
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.jpg", result.ResourceID, result.SessionID))
+		var err error
+		if url.Format == brightcove.ManifestFormatHLS {
+			err = hlsThumbnail(ctx, httpClient, url.URL, path)
+		} else {
+			err = grabFrame(ctx, url.URL, thumbnailOffset, path)
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "error grabbing thumbnail", "session_id", result.SessionID, "err", err)
+			continue
+		}
+		result.Thumbnail = path
+	}
+	return nil
+}
+
+// hlsThumbnail downloads the segment of an HLS VOD's best rendition playing
+// at thumbnailOffset, or its first one for shorter VODs, and grabs a frame of
+// it into path.
+func hlsThumbnail(ctx context.Context, httpClient *http.Client, url, path string) error {
+	body, err := fetchManifest(ctx, httpClient, url)
+	if err != nil {
+		return err
+	}
+
+	mediaURL := url
+	if hls.IsMaster(body) {
+		master, err := hls.ParseMaster(body, url)
+		if err != nil {
+			return fmt.Errorf("error parsing master playlist: %w", err)
+		}
+		if len(master.Variants) == 0 {
+			return errors.New("master playlist has no variants")
+		}
+		best := slices.MaxFunc(master.Variants, func(a, b hls.Variant) int { return a.Bandwidth - b.Bandwidth })
+		mediaURL = withMasterQuery(best.URI, url)
+		if body, err = fetchManifest(ctx, httpClient, mediaURL); err != nil {
+			return err
+		}
+	}
+
+	media, err := hls.ParseMedia(body, mediaURL)
+	if err != nil {
+		return fmt.Errorf("error parsing media playlist: %w", err)
+	}
+	if len(media.Segments) == 0 {
+		return errors.New("media playlist has no segments")
+	}
+	// ffmpeg fetches the keys of encrypted segments itself
+	if media.Encrypted {
+		return grabFrame(ctx, mediaURL, thumbnailOffset, path)
+	}
+
+	segment, seek := media.Segments[0], time.Duration(0)
+	var start time.Duration
+	for _, s := range media.Segments {
+		if start+s.Duration > thumbnailOffset {
+			segment, seek = s, thumbnailOffset-start
+			break
+		}
+		start += s.Duration
+	}
+
+	uris := []string{segment.URI}
+	if media.Map != "" {
+		uris = []string{media.Map, segment.URI}
+	}
+	f, err := os.CreateTemp("", "vodurls-thumbnail-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := downloadSegments(ctx, httpClient, uris, f, len(uris)); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return grabFrame(ctx, f.Name(), seek, path)
+}
+
+// grabFrame writes the frame of input at offset to path as a JPEG with ffmpeg.
+func grabFrame(ctx context.Context, input string, offset time.Duration, path string) error {
+	// A thumbnail of an earlier run must not pass for a new one
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-loglevel", "error", "-y",
+		"-ss", strconv.FormatFloat(offset.Seconds(), 'f', 3, 64),
+		"-i", input,
+		"-frames:v", "1", "-q:v", "2", path,
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running ffmpeg: %w", err)
+	}
+	// ffmpeg exits successfully without writing a frame when offset is past
+	// the end of input
+	if _, err := os.Stat(path); err != nil {
+		return errors.New("ffmpeg grabbed no frame")
+	}
+	return nil
+}
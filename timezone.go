@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	// Embedded so --tz works on hosts without a zoneinfo database, e.g.
+	// scratch containers
+	_ "time/tzdata"
+)
+
+// displayTimeLayout renders timestamps in text output.
+const displayTimeLayout = "2006-01-02 15:04:05 MST"
+
+// displayLocation is the time zone of the timestamps in text output, the local
+// one unless --tz is given. JSON output keeps UTC and epoch values.
+var displayLocation = time.Local
+
+// tzFlag sets displayLocation.
+type tzFlag struct{}
+
+func (tzFlag) String() string {
+	return ""
+}
+
+func (tzFlag) Set(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("unknown time zone %q, expected e.g. Asia/Kolkata or UTC", name)
+	}
+	displayLocation = loc
+	return nil
+}
+
+// registerTZ adds --tz to the flags of a command with text output.
+func registerTZ(fs *flag.FlagSet) {
+	fs.Var(tzFlag{}, "tz", "time zone of the timestamps in text output, e.g. Asia/Kolkata or UTC (default local, env TZ)")
+}
+
+// formatTime renders t for text output.
+func formatTime(t time.Time) string {
+	return t.In(displayLocation).Format(displayTimeLayout)
+}
+
+// formatEpoch renders epoch seconds for text output.
+func formatEpoch(epoch int) string {
+	return formatTime(time.Unix(int64(epoch), 0))
+}
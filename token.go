@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+func runToken(args []string) {
+	fs := flag.NewFlagSet("vodurls token", flag.ExitOnError)
+	setUsage(fs, "Prints an OAuth access token for the client credentials, e.g. to call the Brightcove APIs with curl.",
+		"./vodurls token [--output text|json]",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	output := fs.String("output", outputText, "output format (text or json)")
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if *output != outputText && *output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", *output)
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	client, err := cf.newClient()
+	if err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	token, err := client.AccessToken(ctx)
+	if err != nil {
+		slog.Error("error generating access token", "err", err)
+		exit(exitCode(err))
+	}
+
+	if *output == outputJSON {
+		err = writeJSON(os.Stdout, token)
+	} else {
+		_, err = fmt.Println(token.AccessToken)
+	}
+	if err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+var tracer = otel.Tracer("github.com/rahulbalajee/bc-vod-urls")
+
+var (
+	tracingShutdown     func(context.Context) error
+	tracingShutdownOnce sync.Once
+)
+
+// setupTracing exports spans over OTLP/HTTP when OTEL_TRACES_EXPORTER is otlp
+// or an OTLP endpoint is set. The exporter is configured by the standard
+// OTEL_EXPORTER_OTLP_* variables and the service by OTEL_SERVICE_NAME and
+// OTEL_RESOURCE_ATTRIBUTES.
+func setupTracing(ctx context.Context) error {
+	exporterName := os.Getenv("OTEL_TRACES_EXPORTER")
+	if exporterName == "" && (os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "") {
+		exporterName = "otlp"
+	}
+	switch exporterName {
+	case "", "none":
+		return nil
+	case "otlp":
+	default:
+		return fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q, expected otlp or none", exporterName)
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return fmt.Errorf("error creating trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName("vodurls")))
+	if err != nil {
+		return fmt.Errorf("error creating trace resource: %w", err)
+	}
+	// OTEL_SERVICE_NAME wins over the default service name
+	res, err = resource.Merge(res, resource.Environment())
+	if err != nil {
+		return fmt.Errorf("error creating trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	tracingShutdown = provider.Shutdown
+	return nil
+}
+
+// shutdownTracing flushes the spans not exported yet. It is safe to call more
+// than once.
+func shutdownTracing() {
+	tracingShutdownOnce.Do(func() {
+		if tracingShutdown == nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracingShutdown(ctx); err != nil {
+			slog.Error("error flushing traces", "err", err)
+		}
+	})
+}
+
+// exit flushes pending spans and exits with code. Commands exit through it
+// instead of os.Exit so spans of failed runs are exported too.
+func exit(code int) {
+	shutdownTracing()
+	os.Exit(code)
+}
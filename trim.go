@@ -0,0 +1,121 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// minEpoch tells absolute epoch times apart from plain second offsets.
+const minEpoch = 1_000_000_000
+
+// trimPoint is a --trim-start or --trim-end value: either an offset from the
+// session boundary or an absolute epoch time.
+type trimPoint struct {
+	offset time.Duration
+	epoch  int
+}
+
+func (p trimPoint) isZero() bool {
+	return p.offset == 0 && p.epoch == 0
+}
+
+// parseTrimPoint accepts a duration like 90s or 2m30s, a number of seconds, or
+// an absolute epoch time in seconds.
+func parseTrimPoint(value string) (trimPoint, error) {
+	if value == "" {
+		return trimPoint{}, nil
+	}
+
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 0 {
+			return trimPoint{}, fmt.Errorf("invalid trim %q, must not be negative", value)
+		}
+		if n >= minEpoch {
+			return trimPoint{epoch: n}, nil
+		}
+		return trimPoint{offset: time.Duration(n) * time.Second}, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return trimPoint{}, fmt.Errorf("invalid trim %q, expected a duration, seconds or an epoch time", value)
+	}
+	if d < 0 {
+		return trimPoint{}, fmt.Errorf("invalid trim %q, must not be negative", value)
+	}
+	return trimPoint{offset: d}, nil
+}
+
+// trimRange cuts the beginning and end of sessions before tokens are requested.
+type trimRange struct {
+	start trimPoint
+	end   trimPoint
+}
+
+func parseTrimRange(start, end string) (trimRange, error) {
+	var t trimRange
+	var err error
+	if t.start, err = parseTrimPoint(start); err != nil {
+		return trimRange{}, err
+	}
+	if t.end, err = parseTrimPoint(end); err != nil {
+		return trimRange{}, err
+	}
+	return t, nil
+}
+
+func (t trimRange) empty() bool {
+	return t.start.isZero() && t.end.isZero()
+}
+
+// apply returns the sessions with their start and end times moved inwards.
+// Offsets are relative to each session, epoch times are clamped to it.
+// Sessions left with nothing to play are dropped. A trimmed session keeps its
+// actual end as BroadcastEndTime, its VOD window doesn't close any earlier.
+func (t trimRange) apply(sessions *brightcove.Sessions) (*brightcove.Sessions, error) {
+	if t.empty() {
+		return sessions, nil
+	}
+
+	trimmed := &brightcove.Sessions{}
+	for _, session := range sessions.Events {
+		// A live session has no end to trim, --skip-live drops it later
+		if session.EndTime == 0 {
+			trimmed.Events = append(trimmed.Events, session)
+			continue
+		}
+
+		start, end := session.StartTime, session.EndTime
+
+		if t.start.epoch != 0 {
+			start = max(start, t.start.epoch)
+		} else {
+			start += int(t.start.offset.Seconds())
+		}
+		if t.end.epoch != 0 {
+			end = min(end, t.end.epoch)
+		} else {
+			end -= int(t.end.offset.Seconds())
+		}
+
+		if start >= end {
+			slog.Warn("skipping session, nothing left after trimming", "session_id", session.ID)
+			continue
+		}
+
+		session.BroadcastEndTime = cmp.Or(session.BroadcastEndTime, session.EndTime)
+		session.StartTime, session.EndTime = start, end
+		trimmed.Events = append(trimmed.Events, session)
+	}
+
+	if len(trimmed.Events) == 0 {
+		return nil, fmt.Errorf("no session left after trimming")
+	}
+
+	return trimmed, nil
+}
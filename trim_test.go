@@ -0,0 +1,113 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+func TestParseTrimPoint(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    trimPoint
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "90", want: trimPoint{offset: 90 * time.Second}},
+		{value: "2m30s", want: trimPoint{offset: 150 * time.Second}},
+		{value: "1700000000", want: trimPoint{epoch: 1_700_000_000}},
+		{value: "-5", wantErr: true},
+		{value: "-1m", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseTrimPoint(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimRange(t *testing.T) {
+	const start = 1_700_000_000
+	sessions := &brightcove.Sessions{Events: []brightcove.Session{
+		{ID: "hour", StartTime: start, EndTime: start + 3600},
+		{ID: "minute", StartTime: start + 7200, EndTime: start + 7260},
+	}}
+
+	tests := []struct {
+		name    string
+		trim    trimRange
+		want    []brightcove.Session
+		wantErr bool
+	}{
+		{name: "untrimmed", want: sessions.Events},
+		{
+			name: "offsets",
+			trim: trimRange{start: trimPoint{offset: time.Minute}, end: trimPoint{offset: 2 * time.Minute}},
+			// Nothing is left of the minute long session
+			want: []brightcove.Session{{ID: "hour", StartTime: start + 60, EndTime: start + 3480, BroadcastEndTime: start + 3600}},
+		},
+		{
+			name: "epochs",
+			trim: trimRange{start: trimPoint{epoch: start + 1800}, end: trimPoint{epoch: start + 7230}},
+			want: []brightcove.Session{
+				{ID: "hour", StartTime: start + 1800, EndTime: start + 3600, BroadcastEndTime: start + 3600},
+				{ID: "minute", StartTime: start + 7200, EndTime: start + 7230, BroadcastEndTime: start + 7260},
+			},
+		},
+		{name: "nothing left", trim: trimRange{start: trimPoint{offset: 2 * time.Hour}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimmed, err := tt.trim.apply(sessions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if err == nil && !slices.Equal(trimmed.Events, tt.want) {
+				t.Errorf("got %+v, want %+v", trimmed.Events, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimRangeLive(t *testing.T) {
+	live := brightcove.Session{ID: "live", StartTime: 1_700_000_000}
+	trim := trimRange{start: trimPoint{offset: time.Hour}, end: trimPoint{offset: time.Hour}}
+
+	// Kept untrimmed for --skip-live to drop or the live check to refuse
+	trimmed, err := trim.apply(&brightcove.Sessions{Events: []brightcove.Session{live}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(trimmed.Events, []brightcove.Session{live}) {
+		t.Errorf("got %+v, want the live session", trimmed.Events)
+	}
+}
+
+func TestTrimVODWindow(t *testing.T) {
+	const vodWindowDays = 14
+	end := time.Now().AddDate(0, 0, 1-vodWindowDays)
+	session := brightcove.Session{ID: "long", StartTime: int(end.AddDate(0, 0, -3).Unix()), EndTime: int(end.Unix())}
+
+	// Trimmed, the session ends outside the VOD window, the broadcast didn't
+	trim := trimRange{end: trimPoint{offset: 48 * time.Hour}}
+	trimmed, err := trim.apply(&brightcove.Sessions{Events: []brightcove.Session{session}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := trimmed.Events[0]
+	if !got.WithinVODWindow(vodWindowDays) {
+		t.Errorf("trimmed session outside the VOD window")
+	}
+	if expiry, want := got.VODExpiry(vodWindowDays), session.VODExpiry(vodWindowDays); !expiry.Equal(want) {
+		t.Errorf("trimmed session expires %s, want %s", expiry, want)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rahulbalajee/bc-vod-urls/internal/upload"
+)
+
+// uploadKeyData is the data the object key template of --upload is executed
+// with, once per resource.
+type uploadKeyData struct {
+	// Date is the UTC date of the run, as 2006-01-02.
+	Date string
+	// Time is the UTC time of the run, as 20060102T150405Z.
+	Time       string
+	AccountID  string
+	ResourceID string
+}
+
+// uploadNotifier writes the results of a run to an S3 or GCS bucket, as JSON
+// or CSV depending on the extension of the object key.
+type uploadNotifier struct {
+	bucket upload.Bucket
+	key    *template.Template
+	csv    bool
+}
+
+// newUploadNotifier opens the bucket of location, given as
+// s3://<bucket>/<key template> or gs://<bucket>/<key template>.
+func newUploadNotifier(ctx context.Context, location string) (*uploadNotifier, error) {
+	bucket, key, err := upload.Open(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("key").Option("missingkey=error").Parse(key)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing upload key template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, uploadKeyData{}); err != nil {
+		return nil, fmt.Errorf("invalid upload key template: %w", err)
+	}
+
+	return &uploadNotifier{
+		bucket: bucket,
+		key:    tmpl,
+		csv:    strings.EqualFold(path.Ext(key), ".csv"),
+	}, nil
+}
+
+// notify uploads one object per distinct key. Resources whose key renders the
+// same, e.g. when the template doesn't reference the resource, share an
+// object.
+func (n *uploadNotifier) notify(ctx context.Context, batch batchResult) error {
+	now := time.Now().UTC()
+
+	var keys []string
+	objects := map[string]batchResult{}
+	for _, result := range batch {
+		var buf strings.Builder
+		err := n.key.Execute(&buf, uploadKeyData{
+			Date:       now.Format(time.DateOnly),
+			Time:       now.Format("20060102T150405Z"),
+			AccountID:  result.AccountID,
+			ResourceID: result.ResourceID,
+		})
+		if err != nil {
+			return fmt.Errorf("error rendering upload key: %w", err)
+		}
+
+		key := strings.TrimPrefix(buf.String(), "/")
+		if _, ok := objects[key]; !ok {
+			keys = append(keys, key)
+		}
+		objects[key] = append(objects[key], result)
+	}
+
+	for _, key := range keys {
+		var buf bytes.Buffer
+		contentType := "application/json"
+		if n.csv {
+			contentType = "text/csv"
+			if err := writeCSV(&buf, objects[key]); err != nil {
+				return fmt.Errorf("error encoding CSV: %w", err)
+			}
+		} else if err := writeJSON(&buf, objects[key]); err != nil {
+			return fmt.Errorf("error encoding JSON: %w", err)
+		}
+
+		if err := n.bucket.Put(ctx, key, buf.Bytes(), contentType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// csvHeader are the columns of CSV results, one row per URL.
+var csvHeader = []string{"account_id", "resource_id", "session_id", "start_time", "end_time", "format", "url", "expires_at", "short_url"}
+
+// writeCSV writes the URLs of batch as CSV rows. Resources that failed have no
+// rows.
+func writeCSV(w io.Writer, batch batchResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, result := range batch {
+		for _, session := range result.Sessions {
+			for _, url := range session.URLs {
+				var expiresAt string
+				if !url.ExpiresAt.IsZero() {
+					expiresAt = url.ExpiresAt.UTC().Format(time.RFC3339)
+				}
+				err := cw.Write([]string{
+					session.AccountID,
+					session.ResourceID,
+					session.SessionID,
+					formatEpoch(session.StartTime),
+					formatEpoch(session.EndTime),
+					url.Format,
+					url.URL,
+					expiresAt,
+					url.ShortURL,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rahulbalajee/bc-vod-urls/pkg/brightcove"
+)
+
+// maxManifestSize caps how much of a manifest is read when verifying it.
+const maxManifestSize = 4 << 20
+
+// verifyResults fetches the manifest behind every generated URL, marks the
+// URLs whose manifest can't be fetched or isn't valid and records the caption
+// languages of the others. Those of a rendition picked with --max-resolution
+// or --audio-only are read from its master playlist.
+func verifyResults(ctx context.Context, httpClient *http.Client, results []sessionResult) {
+	for i := range results {
+		for j := range results[i].URLs {
+			url := &results[i].URLs[j]
+
+			captions, err := verifyManifest(ctx, httpClient, url.URL, url.Format)
+			if err == nil && url.MasterURL != "" {
+				var masterErr error
+				if captions, masterErr = verifyManifest(ctx, httpClient, url.MasterURL, url.Format); masterErr != nil {
+					slog.WarnContext(ctx, "error reading the captions of the master playlist", "session_id", results[i].SessionID, "err", masterErr)
+				}
+			}
+			verified := err == nil
+			url.Verified = &verified
+			url.Captions = captions
+			if err != nil {
+				url.VerifyError = err.Error()
+				slog.WarnContext(ctx, "VOD URL failed verification", "session_id", results[i].SessionID, "format", url.Format, "err", err)
+			}
+		}
+	}
+}
+
+// verifyManifest checks that url serves a valid manifest of the given format
+// and returns the languages of its caption tracks.
+func verifyManifest(ctx context.Context, httpClient *http.Client, url, format string) ([]string, error) {
+	body, err := fetchManifest(ctx, httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+
+	body = bytes.TrimLeft(body, "\ufeff \t\r\n")
+	switch format {
+	case brightcove.ManifestFormatDASH:
+		if !bytes.Contains(body[:min(len(body), 4096)], []byte("<MPD")) {
+			return nil, errors.New("response is not an MPD manifest")
+		}
+		return dashCaptions(body)
+	default:
+		if !bytes.HasPrefix(body, []byte("#EXTM3U")) {
+			return nil, errors.New("response is not an HLS playlist")
+		}
+		return hlsCaptions(body, url)
+	}
+}
+
+// fetchManifest downloads a manifest, failing on anything but a 200.
+func fetchManifest(ctx context.Context, httpClient *http.Client, url string) ([]byte, error) {
+	return fetchBody(ctx, httpClient, url, maxManifestSize)
+}
+
+// fetchBody downloads url, reading at most limit bytes or the whole
+// body if limit is 0.
+func fetchBody(ctx context.Context, httpClient *http.Client, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error framing request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+
+	var r io.Reader = resp.Body
+	if limit > 0 {
+		r = io.LimitReader(resp.Body, limit)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	return body, nil
+}
+
+// unverified counts the URLs that failed verification.
+func unverified(results []sessionResult) int {
+	var n int
+	for _, result := range results {
+		for _, url := range result.URLs {
+			if url.Verified != nil && !*url.Verified {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// verifyResult is the outcome of verifying a single URL with the verify
+// command.
+type verifyResult struct {
+	URL      string `json:"url"`
+	Format   string `json:"format"`
+	Verified bool   `json:"verified"`
+	// Captions are the languages of the caption tracks of a verified
+	// manifest, empty when it has none and nil when a media playlist doesn't
+	// tell.
+	Captions []string `json:"captions,omitzero"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("vodurls verify", flag.ExitOnError)
+	setUsage(fs, "Fetches VOD URLs and checks they serve a valid HLS or DASH manifest.",
+		"./vodurls verify [--output text|json] <VOD_URL>...",
+		"./vodurls verify [--output text|json] --input <FILE|->",
+	)
+	var cf clientFlags
+	cf.register(fs)
+	output := fs.String("output", outputText, "output format (text or json)")
+	input := fs.String("input", "", "file with one VOD URL per line, or - for stdin")
+	if err := cf.parse(fs, args); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if err := cf.setupLogging(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	if fs.NArg() == 0 && *input == "" {
+		fs.Usage()
+		exit(exitUsage)
+	}
+
+	if *output != outputText && *output != outputJSON {
+		slog.Error("unsupported output, expected text or json", "output", *output)
+		exit(1)
+	}
+
+	if err := cf.validate(); err != nil {
+		slog.Error(err.Error())
+		exit(1)
+	}
+
+	urls := fs.Args()
+	if *input != "" {
+		var err error
+		if urls, err = readInputs(*input); err != nil {
+			slog.Error("error reading input", "err", err)
+			exit(1)
+		}
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	httpClient := cf.mediaClient()
+	results := make([]verifyResult, 0, len(urls))
+	var failed int
+	for _, url := range urls {
+		result := verifyResult{URL: url, Format: brightcove.ManifestFormatHLS}
+		if strings.Contains(strings.ToLower(url), ".mpd") {
+			result.Format = brightcove.ManifestFormatDASH
+		}
+
+		captions, err := verifyManifest(ctx, httpClient, url, result.Format)
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		} else {
+			result.Verified, result.Captions = true, captions
+		}
+		results = append(results, result)
+	}
+
+	var err error
+	if *output == outputJSON {
+		err = writeJSON(os.Stdout, results)
+	} else {
+		err = writeVerifyText(os.Stdout, results)
+	}
+	if err != nil {
+		slog.Error("error writing output", "err", err)
+		exit(1)
+	}
+
+	if failed > 0 {
+		slog.Error("some VOD URLs failed verification", "unverified", failed, "total", len(results))
+		exit(exitPartial)
+	}
+}
+
+func writeVerifyText(w io.Writer, results []verifyResult) error {
+	for _, result := range results {
+		if result.Verified {
+			fmt.Fprintf(w, "OK %s (captions: %s)\n", result.URL, captionsList(result.Captions))
+		} else {
+			fmt.Fprintf(w, "FAILED %s: %s\n", result.URL, result.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// signatureHeader carries the HMAC-SHA256 of a --post-results body as
+// sha256=<hex>.
+const signatureHeader = "X-Vodurls-Signature-256"
+
+// resultsPayload is the body POSTed by --post-results.
+type resultsPayload struct {
+	GeneratedAt time.Time   `json:"generated_at"`
+	Results     batchResult `json:"results"`
+}
+
+// webhookNotifier POSTs the structured results to an arbitrary endpoint.
+type webhookNotifier struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+func (n *webhookNotifier) notify(ctx context.Context, batch batchResult) error {
+	body, err := json.Marshal(resultsPayload{GeneratedAt: time.Now().UTC(), Results: batch})
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	headers := http.Header{}
+	if n.secret != "" {
+		headers.Set(signatureHeader, "sha256="+sign(n.secret, body))
+	}
+
+	if err := postBody(ctx, n.httpClient, n.url, body, headers); err != nil {
+		return fmt.Errorf("error posting results: %w", err)
+	}
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of body keyed with secret.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}